@@ -0,0 +1,24 @@
+package bot
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Sender — всё, что планировщику и текстам вопросов нужно от Bot API.
+// Реализуется *tgbotapi.BotAPI и Fake.
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// API — Sender плюс приём апдейтов и регистрация вебхука; это нужно
+// только обработчикам.
+type API interface {
+	Sender
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+}
+
+var _ API = (*tgbotapi.BotAPI)(nil)
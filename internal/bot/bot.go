@@ -6,7 +6,8 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// Bot is a thin wrapper that exposes underlying API plus a blocking Run().
+// Bot is a thin wrapper that exposes underlying API.
+// Lifecycle (receiving updates, shutdown) lives in handlers.
 type Bot struct {
 	API *tgbotapi.BotAPI
 }
@@ -22,8 +23,3 @@ func New(token string) (*Bot, error) {
 	api.Debug = false
 	return &Bot{API: api}, nil
 }
-
-// Run blocks forever; all handling goroutines are launched elsewhere.
-func (b *Bot) Run() {
-	select {}
-}
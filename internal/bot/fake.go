@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Fake — Bot API в памяти для сквозных тестов. Запоминает всё, что бот
+// отправил, нумерует сообщения, отдаёт обработчикам апдейты, которые
+// подкладывает тест (Inject), и раздаёт «загруженные» файлы по HTTP.
+//
+//	clk := clock.NewFake(start)
+//	f := bot.NewFake()
+//	f.Now = clk.Now
+//	db.SetClock(clk)
+//	h, _ := handlers.Register(ctx, f, db, clk, cfg)
+//	f.Inject(f.Text(42, "/start"))
+//	sent, _ := f.WaitSent(2, time.Second)
+type Fake struct {
+	// Now — время новых сообщений; тест может подменить часы.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	lastID  int
+	sent    []Sent
+	files   map[string][]byte
+	srv     *httptest.Server
+	updates chan tgbotapi.Update
+}
+
+// Sent — одно обращение бота к API.
+type Sent struct {
+	Method string // sendMessage, editMessageText, answerCallbackQuery, …
+	ChatID int64
+	MsgID  int    // новое сообщение для send*, изменяемое для edit* и delete*
+	Text   string // текст, подпись или текст ответа на callback
+	Markup any    // ReplyMarkup как его передал бот
+	Config tgbotapi.Chattable
+}
+
+// Buttons — callback data inline-кнопок отправленного сообщения по рядам.
+func (s Sent) Buttons() [][]string {
+	var kb *tgbotapi.InlineKeyboardMarkup
+	switch m := s.Markup.(type) {
+	case tgbotapi.InlineKeyboardMarkup:
+		kb = &m
+	case *tgbotapi.InlineKeyboardMarkup:
+		kb = m
+	}
+	if kb == nil {
+		return nil
+	}
+	rows := make([][]string, 0, len(kb.InlineKeyboard))
+	for _, row := range kb.InlineKeyboard {
+		var r []string
+		for _, b := range row {
+			if b.CallbackData != nil {
+				r = append(r, *b.CallbackData)
+			}
+		}
+		rows = append(rows, r)
+	}
+	return rows
+}
+
+var _ API = (*Fake)(nil)
+
+func NewFake() *Fake {
+	return &Fake{
+		Now:     time.Now,
+		files:   map[string][]byte{},
+		updates: make(chan tgbotapi.Update, 100),
+	}
+}
+
+// Close останавливает файловый сервер, если он запускался.
+func (f *Fake) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.srv != nil {
+		f.srv.Close()
+	}
+}
+
+func (f *Fake) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s := f.record(c)
+	return tgbotapi.Message{
+		MessageID: s.MsgID,
+		Chat:      &tgbotapi.Chat{ID: s.ChatID},
+		Date:      int(f.Now().Unix()),
+		Text:      s.Text,
+	}, nil
+}
+
+func (f *Fake) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.record(c)
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("true")}, nil
+}
+
+func (f *Fake) MakeRequest(endpoint string, _ tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	f.append(Sent{Method: endpoint})
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("true")}, nil
+}
+
+func (f *Fake) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return f.updates
+}
+
+func (f *Fake) StopReceivingUpdates() {}
+
+// GetFileDirectURL отдаёт ссылку на файл, добавленный через Document.
+func (f *Fake) GetFileDirectURL(fileID string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.files[fileID]; !ok {
+		return "", fmt.Errorf("fake: нет файла %q", fileID)
+	}
+	return f.srv.URL + "/" + fileID, nil
+}
+
+// Inject передаёт апдейт обработчикам, как будто он пришёл от Telegram.
+func (f *Fake) Inject(upd tgbotapi.Update) {
+	f.updates <- upd
+}
+
+// Text — апдейт «пользователь написал text»; /команда размечается как команда.
+func (f *Fake) Text(chatID int64, text string) tgbotapi.Update {
+	msg := f.message(chatID)
+	msg.Text = text
+	if strings.HasPrefix(text, "/") {
+		cmd, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Length: len(cmd)}}
+	}
+	return tgbotapi.Update{UpdateID: msg.MessageID, Message: msg}
+}
+
+// Press — апдейт «пользователь нажал кнопку data под сообщением msgID».
+func (f *Fake) Press(chatID int64, msgID int, data string) tgbotapi.Update {
+	f.mu.Lock()
+	f.lastID++
+	id := f.lastID
+	f.mu.Unlock()
+	return tgbotapi.Update{UpdateID: id, CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   fmt.Sprint(id),
+		From: &tgbotapi.User{ID: chatID},
+		Message: &tgbotapi.Message{
+			MessageID: msgID,
+			Chat:      &tgbotapi.Chat{ID: chatID},
+			Date:      int(f.Now().Unix()),
+		},
+		Data: data,
+	}}
+}
+
+// Document — апдейт «пользователь прислал файл name»; содержимое потом
+// скачивается по GetFileDirectURL.
+func (f *Fake) Document(chatID int64, name string, data []byte) tgbotapi.Update {
+	msg := f.message(chatID)
+	fileID := fmt.Sprintf("file%d", msg.MessageID)
+
+	f.mu.Lock()
+	f.files[fileID] = data
+	if f.srv == nil {
+		f.srv = httptest.NewServer(http.HandlerFunc(f.serveFile))
+	}
+	f.mu.Unlock()
+
+	msg.Document = &tgbotapi.Document{
+		FileID:       fileID,
+		FileUniqueID: "u" + fileID,
+		FileName:     name,
+		FileSize:     len(data),
+	}
+	return tgbotapi.Update{UpdateID: msg.MessageID, Message: msg}
+}
+
+// Sent — копия всех обращений бота к API по порядку.
+func (f *Fake) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
+
+// WaitSent ждёт, пока обращений станет не меньше n: обработчики работают
+// в своей горутине. false — не дождались за timeout.
+func (f *Fake) WaitSent(n int, timeout time.Duration) ([]Sent, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		sent := f.Sent()
+		if len(sent) >= n {
+			return sent, true
+		}
+		if time.Now().After(deadline) {
+			return sent, false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Reset забывает отправленное.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
+
+func (f *Fake) message(chatID int64) *tgbotapi.Message {
+	f.mu.Lock()
+	f.lastID++
+	id := f.lastID
+	f.mu.Unlock()
+	return &tgbotapi.Message{
+		MessageID: id,
+		From:      &tgbotapi.User{ID: chatID},
+		Chat:      &tgbotapi.Chat{ID: chatID},
+		Date:      int(f.Now().Unix()),
+	}
+}
+
+func (f *Fake) serveFile(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	data, ok := f.files[strings.TrimPrefix(r.URL.Path, "/")]
+	f.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+// record раскладывает известные боту конфиги по полям Sent
+func (f *Fake) record(c tgbotapi.Chattable) Sent {
+	s := Sent{Config: c}
+	switch c := c.(type) {
+	case tgbotapi.MessageConfig:
+		s.Method, s.ChatID, s.Text, s.Markup = "sendMessage", c.ChatID, c.Text, c.ReplyMarkup
+	case tgbotapi.PhotoConfig:
+		s.Method, s.ChatID, s.Text, s.Markup = "sendPhoto", c.ChatID, c.Caption, c.ReplyMarkup
+	case tgbotapi.DocumentConfig:
+		s.Method, s.ChatID, s.Text, s.Markup = "sendDocument", c.ChatID, c.Caption, c.ReplyMarkup
+	case tgbotapi.EditMessageTextConfig:
+		s.Method, s.ChatID, s.MsgID, s.Text = "editMessageText", c.ChatID, c.MessageID, c.Text
+		if c.ReplyMarkup != nil {
+			s.Markup = c.ReplyMarkup
+		}
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		s.Method, s.ChatID, s.MsgID = "editMessageReplyMarkup", c.ChatID, c.MessageID
+		if c.ReplyMarkup != nil {
+			s.Markup = c.ReplyMarkup
+		}
+	case tgbotapi.DeleteMessageConfig:
+		s.Method, s.ChatID, s.MsgID = "deleteMessage", c.ChatID, c.MessageID
+	case tgbotapi.CallbackConfig:
+		s.Method, s.Text = "answerCallbackQuery", c.Text
+	default:
+		s.Method = fmt.Sprintf("%T", c)
+	}
+	return f.append(s)
+}
+
+// append сохраняет обращение; send* получают номер нового сообщения
+func (f *Fake) append(s Sent) Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if strings.HasPrefix(s.Method, "send") {
+		f.lastID++
+		s.MsgID = f.lastID
+	}
+	f.sent = append(f.sent, s)
+	return s
+}
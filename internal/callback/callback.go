@@ -0,0 +1,189 @@
+// Package callback — формат callback data inline-кнопок.
+//
+// Data кнопки: "<версия>|<действие>|<дата>|<payload>", например
+// "1|ate_now|20250508e|" — «Поел» под вечерним вопросом 8 мая. Дата — ключ
+// вопроса в сжатом виде (2025-05-08-evening → 20250508e, 2025-05-08 →
+// 20250508), payload — остаток, смысл которого знает действие.
+//
+// Telegram ограничивает data 64 байтами и хранит её в сообщении вечно,
+// поэтому формат версионирован: кнопку прежней версии Decode не разберёт
+// и вернёт ErrStale, а бот скажет, что кнопка устарела.
+package callback
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Version — текущая версия формата. Кнопки без версии появились до него.
+const Version = "1"
+
+// MaxLen — ограничение Telegram на callback data.
+const MaxLen = 64
+
+const sep = "|"
+
+// ErrStale — кнопка из старой версии бота или с битыми данными.
+var ErrStale = errors.New("callback: устаревшая кнопка")
+
+// Action — что делает кнопка.
+type Action string
+
+const (
+	CfgConfirm Action = "cfg_ok"
+	CfgChange  Action = "cfg_edit"
+	CfgCancel  Action = "cfg_x"
+	CfgRemind  Action = "cfg_rem" // политика напоминаний
+
+	AteNow Action = "ate_now" // дата — вечерний вопрос
+	AteAt  Action = "ate_at"
+
+	Snooze    Action = "snooze" // дата — вопрос, payload — минуты
+	SkipToday Action = "skip"   // дата — вопрос
+
+	CheckNone    Action = "ck_none"  // чек-ин, дата — утренний вопрос
+	CheckScore   Action = "ck_score" // payload — оценка 0–10
+	CheckSymptom Action = "ck_sym"   // payload — ключ симптома
+	CheckNote    Action = "ck_note"
+	CheckDone    Action = "ck_done"
+	DinnerYes    Action = "din_ok" // подтверждение ужина, дата — вечерний вопрос
+	DinnerCancel Action = "din_x"
+	DinnerTag    Action = "din_t" // дата — вечерний вопрос, payload — тег
+	DinnerTagsOK Action = "din_tok"
+
+	SymAdd     Action = "sym_add"
+	SymRename  Action = "sym_ren" // payload — ключ симптома
+	SymUp      Action = "sym_up"
+	SymArchive Action = "sym_arc"
+	SymRestore Action = "sym_rst"
+	SymFreq    Action = "sym_freq" // payload — период в днях, пусто — из меню
+
+	MealKind   Action = "meal_k" // дневник питания, дата — день; payload — вид
+	MealNow    Action = "meal_now"
+	MealSkip   Action = "meal_skip"
+	MealTag    Action = "meal_t" // payload — ключ тега
+	MealDone   Action = "meal_ok"
+	MealDay    Action = "meal_d" // дата — день
+	MealAdd    Action = "meal_add"
+	MealPhotos Action = "meal_ph"
+	MealDelete Action = "meal_del" // payload — id записи
+
+	MissedFill Action = "miss_fill" // дата — пропущенный вопрос
+	MissedSkip Action = "miss_skip" // дата — один вопрос, без даты — все пропущенные
+
+	Stats    Action = "stats" // payload — период в днях
+	Chart    Action = "chart" // payload — вид графика
+	Triggers Action = "trig"
+
+	HistMonth Action = "hist_m"  // payload — месяц YYYY-MM
+	HistDay   Action = "hist_d"  // дата — день
+	HistEditM Action = "hist_em" // дата — день
+	HistEditE Action = "hist_ee"
+	HistNop   Action = "hist_nop" // пустые клетки календаря
+
+	ExportRange  Action = "exp_r" // payload — дни, 0 — всё время
+	ExportCustom Action = "exp_c" // свой период текстом
+	ExportFormat Action = "exp_f" // payload — период:формат, период — дни или YYYYMMDD-YYYYMMDD
+
+	ImportApply   Action = "imp_ok"
+	ImportReplace Action = "imp_rep"
+	ImportCancel  Action = "imp_x"
+
+	Lang Action = "lang" // payload — код языка
+)
+
+// Data — разобранная callback data.
+type Data struct {
+	Action  Action
+	DateKey string // 2025-05-08-morning, 2025-05-08 или пусто
+	Payload string
+}
+
+// Encode собирает data кнопки. Данные формирует код, а не пользователь,
+// поэтому слишком длинная data — ошибка программы, и Encode паникует.
+func Encode(a Action, dateKey, payload string) string {
+	s := Version + sep + string(a) + sep + packDate(dateKey) + sep + payload
+	if len(s) > MaxLen {
+		panic(fmt.Sprintf("callback: data %q длиннее %d байт", s, MaxLen))
+	}
+	return s
+}
+
+// Button — inline-кнопка с data из Encode.
+func Button(text string, a Action, dateKey, payload string) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, Encode(a, dateKey, payload))
+}
+
+// Decode разбирает data; кнопки других версий — ErrStale.
+func Decode(s string) (Data, error) {
+	parts := strings.SplitN(s, sep, 4)
+	if len(parts) != 4 || parts[0] != Version || parts[1] == "" {
+		return Data{}, ErrStale
+	}
+	dateKey, ok := unpackDate(parts[2])
+	if !ok {
+		return Data{}, ErrStale
+	}
+	return Data{Action: Action(parts[1]), DateKey: dateKey, Payload: parts[3]}, nil
+}
+
+// Day — дата без части дня: 2025-05-08-morning → 2025-05-08.
+func (d Data) Day() string {
+	if len(d.DateKey) < 10 {
+		return d.DateKey
+	}
+	return d.DateKey[:10]
+}
+
+var kinds = map[string]string{"-morning": "m", "-evening": "e"}
+
+// packDate: 2025-05-08-evening → 20250508e
+func packDate(dateKey string) string {
+	if dateKey == "" {
+		return ""
+	}
+	if len(dateKey) < 10 {
+		panic(fmt.Sprintf("callback: неверный ключ даты %q", dateKey))
+	}
+	day, kind := dateKey[:10], dateKey[10:]
+	s := strings.ReplaceAll(day, "-", "")
+	if kind != "" {
+		k, ok := kinds[kind]
+		if !ok {
+			panic(fmt.Sprintf("callback: неверный ключ даты %q", dateKey))
+		}
+		s += k
+	}
+	return s
+}
+
+func unpackDate(s string) (string, bool) {
+	if s == "" {
+		return "", true
+	}
+	if len(s) != 8 && len(s) != 9 {
+		return "", false
+	}
+	t, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return "", false
+	}
+	key := t.Format("2006-01-02")
+	if len(s) == 9 {
+		kind := ""
+		for k, v := range kinds {
+			if v == s[8:] {
+				kind = k
+			}
+		}
+		if kind == "" {
+			return "", false
+		}
+		key += kind
+	}
+	return key, true
+}
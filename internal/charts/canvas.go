@@ -0,0 +1,128 @@
+package charts
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+var (
+	white    = color.RGBA{255, 255, 255, 255}
+	black    = color.RGBA{40, 40, 40, 255}
+	grey     = color.RGBA{150, 150, 150, 255}
+	gridGrey = color.RGBA{225, 225, 225, 255}
+	empty    = color.RGBA{235, 235, 235, 255}
+	blue     = color.RGBA{52, 120, 200, 255}
+	red      = color.RGBA{220, 70, 70, 255}
+	green    = color.RGBA{80, 170, 100, 255}
+)
+
+// canvas — минимальный растровый холст: прямоугольники, линии, текст.
+type canvas struct {
+	img   *image.RGBA
+	faces map[float64]font.Face // font.Face не потокобезопасен — свой на каждый холст
+}
+
+func newCanvas(w, h int) *canvas {
+	c := &canvas{
+		img:   image.NewRGBA(image.Rect(0, 0, w, h)),
+		faces: map[float64]font.Face{},
+	}
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+	return c
+}
+
+func (c *canvas) rect(x0, y0, x1, y1 int, col color.Color) {
+	draw.Draw(c.img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// line — отрезок толщиной w (Брезенхем, точка — квадрат w×w)
+func (c *canvas) line(x0, y0, x1, y1, w int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		c.rect(x0-w/2, y0-w/2, x0-w/2+w, y0-w/2+w, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// text пишет строку; (x, y) — левый край и базовая линия
+func (c *canvas) text(x, y int, s string, size float64, col color.Color) {
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.NewUniform(col),
+		Face: c.face(size),
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// textCenter центрирует строку относительно x
+func (c *canvas) textCenter(x, y int, s string, size float64, col color.Color) {
+	w := font.MeasureString(c.face(size), s).Round()
+	c.text(x-w/2, y, s, size, col)
+}
+
+func (c *canvas) png() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// goRegular — Go Regular покрывает кириллицу и встроен в бинарник
+var goRegular = sync.OnceValue(func() *opentype.Font {
+	ft, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		panic(err) // встроенный шрифт не может не разобраться
+	}
+	return ft
+})
+
+func (c *canvas) face(size float64) font.Face {
+	if f, ok := c.faces[size]; ok {
+		return f
+	}
+	f, err := opentype.NewFace(goRegular(), &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		panic(err)
+	}
+	c.faces[size] = f
+	return f
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+// Package charts рисует графики дневника в PNG без внешних сервисов.
+package charts
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+)
+
+// Kind — вид графика; значения идут в callback data.
+type Kind string
+
+const (
+	Dinner  Kind = "dinner"
+	Heatmap Kind = "heatmap"
+	Answers Kind = "answers"
+)
+
+// Kinds — в этом порядке показываем кнопки.
+var Kinds = []Kind{Dinner, Heatmap, Answers}
+
+// Title — подпись кнопки и картинки.
+func Title(l i18n.Lang, k Kind) string {
+	return l.T("chart." + string(k))
+}
+
+// Days — сколько дней истории нужно каждому графику.
+var Days = map[Kind]int{
+	Dinner:  30,
+	Heatmap: 12 * 7,
+	Answers: 12 * 7,
+}
+
+const dayLayout = "2006-01-02"
+
+// Render рисует график kind по записям за Days[kind] дней до now (в поясе пользователя).
+func Render(l i18n.Lang, kind Kind, recs []models.DayRecord, now time.Time) ([]byte, error) {
+	byDay := make(map[string]models.DayRecord, len(recs))
+	for _, rec := range recs {
+		byDay[rec.Day] = rec
+	}
+
+	switch kind {
+	case Dinner:
+		return dinnerLine(l, byDay, now)
+	case Heatmap:
+		return complaintHeatmap(l, byDay, now)
+	case Answers:
+		return weeklyAnswers(l, byDay, now)
+	default:
+		return nil, fmt.Errorf("неизвестный график %q", kind)
+	}
+}
+
+// dinnerLine — время ужина по дням; дни без ужина рвут линию
+func dinnerLine(l i18n.Lang, byDay map[string]models.DayRecord, now time.Time) ([]byte, error) {
+	const (
+		w, h                     = 900, 450
+		left, right, top, bottom = 70, 30, 60, 60
+	)
+	n := Days[Dinner]
+	first := now.AddDate(0, 0, -n+1)
+
+	// ось Y — от 17:00 до 23:00, расширяем под реальные ужины
+	lo, hi := 17*time.Hour, 23*time.Hour
+	vals := make([]*time.Duration, n)
+	for i := 0; i < n; i++ {
+		rec, ok := byDay[first.AddDate(0, 0, i).Format(dayLayout)]
+		if !ok || rec.DinnerAt == nil {
+			continue
+		}
+		v := stats.SinceMidnight(rec.DinnerAt.In(now.Location()))
+		vals[i] = &v
+		lo = min(lo, v.Truncate(time.Hour))
+		hi = max(hi, v.Truncate(time.Hour)+time.Hour)
+	}
+
+	c := newCanvas(w, h)
+	c.text(left, 35, l.T("chart.dinner.title"), 20, black)
+
+	plotW, plotH := w-left-right, h-top-bottom
+	y := func(v time.Duration) int {
+		return top + plotH - int(float64(plotH)*float64(v-lo)/float64(hi-lo))
+	}
+	x := func(i int) int {
+		return left + plotW*i/max(n-1, 1)
+	}
+
+	for v := lo; v <= hi; v += time.Hour {
+		c.line(left, y(v), w-right, y(v), 1, gridGrey)
+		c.text(10, y(v)+5, stats.Clock(v), 13, grey)
+	}
+	for i := 0; i < n; i += 7 {
+		c.textCenter(x(i), h-bottom+25, first.AddDate(0, 0, i).Format("02.01"), 13, grey)
+	}
+
+	for i, v := range vals {
+		if v == nil {
+			continue
+		}
+		if i > 0 && vals[i-1] != nil {
+			c.line(x(i-1), y(*vals[i-1]), x(i), y(*v), 3, blue)
+		}
+		c.rect(x(i)-4, y(*v)-4, x(i)+5, y(*v)+5, blue)
+	}
+	return c.png()
+}
+
+// complaintHeatmap — календарь как на GitHub: столбцы — недели, строки — дни недели
+func complaintHeatmap(l i18n.Lang, byDay map[string]models.DayRecord, now time.Time) ([]byte, error) {
+	const (
+		cell, gap = 26, 4
+		left, top = 50, 80
+		weeks     = 12
+	)
+	// первый столбец начинается с понедельника, поэтому столбцов может быть weeks+1
+	last := now
+	first := last.AddDate(0, 0, -weeks*7+1)
+	first = first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+	cols := daysBetween(first, last)/7 + 1
+
+	w := max(left+cols*(cell+gap)+30, 450)
+	h := top + 7*(cell+gap) + 60
+
+	c := newCanvas(w, h)
+	c.text(left, 35, Title(l, Heatmap), 20, black)
+	for i, wd := range l.List("weekdays") {
+		c.text(10, top+i*(cell+gap)+cell-8, wd, 13, grey)
+	}
+
+	// подпись месяца над первой его неделей, если не налезает на предыдущую
+	prevMonth, prevX := time.Month(0), -100
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		col := daysBetween(first, d) / 7
+		row := (int(d.Weekday()) + 6) % 7
+		x0, y0 := left+col*(cell+gap), top+row*(cell+gap)
+
+		if row == 0 && d.Month() != prevMonth {
+			prevMonth = d.Month()
+			if x0-prevX >= 3*(cell+gap) {
+				c.text(x0, top-10, d.Format("01.2006"), 12, grey)
+				prevX = x0
+			}
+		}
+
+		fill := empty
+		if rec, ok := byDay[d.Format(dayLayout)]; ok && rec.MorningAnswered() {
+			fill = red
+			if stats.Fine(rec) {
+				fill = green
+			}
+		}
+		c.rect(x0, y0, x0+cell, y0+cell, fill)
+	}
+
+	ly := h - 25
+	for i, l := range []struct {
+		col   color.RGBA
+		label string
+	}{{red, l.T("chart.complaints")}, {green, l.T("chart.no_complaints")}, {empty, l.T("chart.no_data")}} {
+		lx := left + i*130
+		c.rect(lx, ly-13, lx+16, ly+3, l.col)
+		c.text(lx+22, ly, l.label, 13, black)
+	}
+	return c.png()
+}
+
+// weeklyAnswers — доля заполненных утр и ужинов по неделям
+func weeklyAnswers(l i18n.Lang, byDay map[string]models.DayRecord, now time.Time) ([]byte, error) {
+	const (
+		w, h                     = 900, 450
+		left, right, top, bottom = 60, 30, 60, 70
+		weeks                    = 12
+	)
+
+	c := newCanvas(w, h)
+	c.text(left, 35, Title(l, Answers), 20, black)
+
+	plotW, plotH := w-left-right, h-top-bottom
+	y := func(rate float64) int { return top + plotH - int(float64(plotH)*rate) }
+	for _, r := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		c.line(left, y(r), w-right, y(r), 1, gridGrey)
+		c.text(10, y(r)+5, fmt.Sprintf("%.0f%%", r*100), 13, grey)
+	}
+
+	slot := plotW / weeks
+	barW := slot / 3
+	for wk := 0; wk < weeks; wk++ {
+		start := now.AddDate(0, 0, -(weeks-wk)*7+1)
+		var morning, evening int
+		for i := 0; i < 7; i++ {
+			rec, ok := byDay[start.AddDate(0, 0, i).Format(dayLayout)]
+			if !ok {
+				continue
+			}
+			if rec.MorningAnswered() {
+				morning++
+			}
+			if rec.DinnerAt != nil {
+				evening++
+			}
+		}
+
+		x0 := left + wk*slot + slot/6
+		c.rect(x0, y(float64(morning)/7), x0+barW, y(0), blue)
+		c.rect(x0+barW, y(float64(evening)/7), x0+2*barW, y(0), green)
+		c.textCenter(x0+barW, h-bottom+22, start.Format("02.01"), 12, grey)
+	}
+
+	ly := h - 20
+	c.rect(left, ly-13, left+16, ly+3, blue)
+	c.text(left+22, ly, l.T("chart.morning"), 13, black)
+	c.rect(left+100, ly-13, left+116, ly+3, green)
+	c.text(left+122, ly, l.T("chart.evening"), 13, black)
+	return c.png()
+}
+
+// daysBetween — целых суток между датами; округляем, чтобы не сбивал переход на летнее время
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours()/24 + 0.5)
+}
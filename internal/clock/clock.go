@@ -0,0 +1,29 @@
+// Package clock — источник времени для планировщика, обработчиков и
+// хранилища. В работе это системные часы, в тестах — Fake: время двигают
+// вручную (Advance), и таймеры планировщика и gocron срабатывают вместе с
+// ним, так что можно прогнать несколько суток, переход на летнее время
+// или полночь за миллисекунды.
+package clock
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+// Clock — часы; совместимы с gocron.WithClock.
+type Clock = clockwork.Clock
+
+// Fake — часы, которые идут только по Advance. BlockUntil(n) ждёт, пока
+// n таймеров не встанут в ожидание — так тест узнаёт, что планировщик уснул.
+type Fake = clockwork.FakeClock
+
+// Real — системные часы.
+func Real() Clock {
+	return clockwork.NewRealClock()
+}
+
+// NewFake — остановленные часы, показывающие t.
+func NewFake(t time.Time) *Fake {
+	return clockwork.NewFakeClockAt(t)
+}
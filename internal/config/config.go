@@ -3,19 +3,153 @@ package config
 import (
 	"log"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	DBName        string
+	DBDriver      string // "sqlite" или "postgres"
+	DBDSN         string // путь к файлу SQLite или строка подключения PostgreSQL
 	TelegramToken string
+	UpdateMode    string // ModePolling или ModeWebhook
+	Webhook       WebhookConfig
+
+	ShutdownTimeout time.Duration // сколько ждать обработчики при остановке
+	SchedulerGrace  time.Duration // насколько поздно ещё можно задать пропущенный вопрос
+	MissedLookback  int           // за сколько дней при старте искать незаданные вопросы
+
+	AdminChatID int64 // кому доступен /reset_all; 0 — никому
+}
+
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+// WebhookConfig — настройки приёма апдейтов через HTTP вместо long polling.
+type WebhookConfig struct {
+	Listen  string // адрес HTTP-сервера, например ":8080"
+	Path    string // путь, на который Telegram шлёт апдейты
+	URL     string // публичный URL; если задан — регистрируем вебхук при старте
+	Secret  string // сверяется с X-Telegram-Bot-Api-Secret-Token
+	TLSCert string // пути к сертификату и ключу; пусто — обычный HTTP за прокси
+	TLSKey  string
 }
 
 func Load() Config {
+	driver, dsn := getDatabase()
+	mode, webhook := getUpdateMode()
 	return Config{
 		DBName:        DBName,
+		DBDriver:      driver,
+		DBDSN:         dsn,
 		TelegramToken: getBotToken(),
+		UpdateMode:    mode,
+		Webhook:       webhook,
+
+		ShutdownTimeout: getDuration("SHUTDOWN_TIMEOUT", 8*time.Second),
+		SchedulerGrace:  getDuration("SCHEDULER_GRACE", 2*time.Hour),
+		MissedLookback:  getInt("MISSED_LOOKBACK_DAYS", 3),
+
+		AdminChatID: int64(getInt("ADMIN_CHAT_ID", 0)),
+	}
+}
+
+// getDuration читает длительность в формате time.ParseDuration ("8s", "2h").
+// SHUTDOWN_TIMEOUT по умолчанию укладывается в 10 секунд, которые docker даёт до SIGKILL;
+// SCHEDULER_GRACE по умолчанию равен длине утреннего/вечернего окна.
+func getDuration(env string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(env))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("❌ Неверный %s %q: ожидается длительность, например 8s", env, v)
+	}
+	return d
+}
+
+// getDatabase: по умолчанию SQLite в DBName; DB_DRIVER=postgres
+// переключает на PostgreSQL, строка подключения берётся из DATABASE_URL.
+func getDatabase() (driver, dsn string) {
+	driver = strings.ToLower(strings.TrimSpace(os.Getenv("DB_DRIVER")))
+	switch driver {
+	case "", "sqlite":
+		return "sqlite", DBName
+	case "postgres":
+		dsn = strings.TrimSpace(os.Getenv("DATABASE_URL"))
+		if dsn == "" {
+			log.Fatal("❌ DB_DRIVER=postgres, но DATABASE_URL не задан")
+		}
+		return driver, dsn
+	default:
+		log.Fatalf("❌ Неизвестный DB_DRIVER %q: ожидается sqlite или postgres", driver)
+		return "", ""
+	}
+}
+
+var secretRx = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// getUpdateMode: UPDATE_MODE=webhook включает HTTP-сервер вместо long polling.
+func getUpdateMode() (string, WebhookConfig) {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("UPDATE_MODE")))
+	switch mode {
+	case "", ModePolling:
+		return ModePolling, WebhookConfig{}
+	case ModeWebhook:
+	default:
+		log.Fatalf("❌ Неизвестный UPDATE_MODE %q: ожидается polling или webhook", mode)
+	}
+
+	wh := WebhookConfig{
+		Listen:  envOr("WEBHOOK_LISTEN", ":8080"),
+		Path:    envOr("WEBHOOK_PATH", "/telegram"),
+		URL:     strings.TrimSpace(os.Getenv("WEBHOOK_URL")),
+		Secret:  getSecret("telegram_webhook_secret", "WEBHOOK_SECRET"),
+		TLSCert: strings.TrimSpace(os.Getenv("WEBHOOK_TLS_CERT")),
+		TLSKey:  strings.TrimSpace(os.Getenv("WEBHOOK_TLS_KEY")),
+	}
+	if !secretRx.MatchString(wh.Secret) {
+		log.Fatal("❌ WEBHOOK_SECRET обязателен: 1–256 символов A-Z, a-z, 0-9, _ и -")
+	}
+	if (wh.TLSCert == "") != (wh.TLSKey == "") {
+		log.Fatal("❌ WEBHOOK_TLS_CERT и WEBHOOK_TLS_KEY задаются только вместе")
+	}
+	return ModeWebhook, wh
+}
+
+// getInt читает положительное целое из переменной окружения
+func getInt(env string, def int) int {
+	v := strings.TrimSpace(os.Getenv(env))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatalf("❌ Неверный %s %q: ожидается положительное число", env, v)
+	}
+	return n
+}
+
+func envOr(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// getSecret: сначала Docker Secret, потом переменная окружения
+func getSecret(name, env string) string {
+	if data, err := os.ReadFile("/run/secrets/" + name); err == nil {
+		if v := strings.TrimSpace(string(data)); v != "" {
+			return v
+		}
 	}
+	return strings.TrimSpace(os.Getenv(env))
 }
 
 func getBotToken() string {
@@ -0,0 +1,104 @@
+// Package export выгружает дневник в файлы для врача или таблиц.
+//
+// Во всех форматах одни и те же колонки (их же ждёт импорт):
+//
+//	day         дата YYYY-MM-DD
+//	complaints  самочувствие утром текстом, «-» — нет жалоб, пусто — не заполнено
+//	score       оценка самочувствия 0–10, пусто — не оценено
+//	symptoms    ключи отмеченных симптомов через запятую (см. models.Symptom)
+//	dinner      время ужина HH:MM в часовом поясе пользователя, пусто — не отмечено
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/models"
+)
+
+// Format — формат выгрузки.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+	XLSX Format = "xlsx"
+)
+
+// Formats — в этом порядке показываем кнопки.
+var Formats = []Format{CSV, JSON, XLSX}
+
+// Columns — заголовки колонок CSV/XLSX.
+var Columns = []string{"day", "complaints", "score", "symptoms", "dinner"}
+
+// Row — один день дневника в выгрузке.
+type Row struct {
+	Day        string `json:"day"`
+	Complaints string `json:"complaints"`
+	Score      string `json:"score"`
+	Symptoms   string `json:"symptoms"`
+	Dinner     string `json:"dinner"`
+}
+
+// cells — значения в порядке Columns.
+func (r Row) cells() []string {
+	return []string{r.Day, r.Complaints, r.Score, r.Symptoms, r.Dinner}
+}
+
+// Rows переводит записи в строки выгрузки, время ужина — в loc.
+func Rows(recs []models.DayRecord, loc *time.Location) []Row {
+	res := make([]Row, 0, len(recs))
+	for _, rec := range recs {
+		r := Row{Day: rec.Day, Complaints: rec.Complaints}
+		if c := rec.CheckIn; c != nil {
+			if c.NoComplaints && r.Complaints == "" {
+				r.Complaints = "-"
+			}
+			if c.Score != nil {
+				r.Score = strconv.Itoa(*c.Score)
+			}
+			r.Symptoms = strings.Join(c.Symptoms, ",")
+		}
+		if rec.DinnerAt != nil {
+			r.Dinner = rec.DinnerAt.In(loc).Format("15:04")
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// Encode рендерит строки в выбранный формат.
+func Encode(f Format, rows []Row) ([]byte, error) {
+	switch f {
+	case CSV:
+		return encodeCSV(rows)
+	case JSON:
+		return json.MarshalIndent(rows, "", "  ")
+	case XLSX:
+		return encodeXLSX(rows)
+	default:
+		return nil, fmt.Errorf("неизвестный формат %q", f)
+	}
+}
+
+func encodeCSV(rows []Row) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF") // BOM — чтобы Excel открыл кириллицу без кракозябр
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(Columns); err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		if err := w.Write(r.cells()); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,247 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+)
+
+// MaxImportRows — больше строк за раз не принимаем.
+const MaxImportRows = 5000
+
+// ImportFormat определяет формат загружаемого файла по расширению;
+// импортировать можно только CSV и JSON.
+func ImportFormat(name string) (Format, bool) {
+	switch f := Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")); f {
+	case CSV, JSON:
+		return f, true
+	default:
+		return "", false
+	}
+}
+
+// Decode читает строки файла в раскладке Columns. Значения не проверяются —
+// это делает Check.
+func Decode(l i18n.Lang, f Format, data []byte) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+
+	var (
+		rows []Row
+		err  error
+	)
+	switch f {
+	case CSV:
+		rows, err = decodeCSV(l, data)
+	case JSON:
+		err = json.Unmarshal(data, &rows)
+	default:
+		return nil, errors.New(l.T("import.unsupported", f))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New(l.T("import.no_rows"))
+	}
+	if len(rows) > MaxImportRows {
+		return nil, errors.New(l.T("import.too_many", len(rows), MaxImportRows))
+	}
+	return rows, nil
+}
+
+// decodeCSV: первая строка — заголовок, колонки в любом порядке.
+// Разделитель «;» тоже принимаем — так сохраняет Excel в русской локали.
+func decodeCSV(l i18n.Lang, data []byte) ([]Row, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	if head, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.New(l.T("import.bad_header", err))
+	}
+	idx := map[string]int{}
+	for i, name := range header {
+		idx[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := idx["day"]; !ok {
+		return nil, errors.New(l.T("import.no_day", strings.Join(Columns, ", ")))
+	}
+	get := func(rec []string, col string) string {
+		if i, ok := idx[col]; ok && i < len(rec) {
+			return rec[i]
+		}
+		return ""
+	}
+
+	var rows []Row
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, Row{
+			Day:        get(rec, "day"),
+			Complaints: get(rec, "complaints"),
+			Score:      get(rec, "score"),
+			Symptoms:   get(rec, "symptoms"),
+			Dinner:     get(rec, "dinner"),
+		})
+	}
+	return rows, nil
+}
+
+// Problem — запись файла, которую импортировать нельзя; N — её номер с 1.
+type Problem struct {
+	N      int
+	Reason string
+}
+
+// Conflict — день уже заполнен в дневнике другими значениями.
+type Conflict struct {
+	N   int
+	New Row
+	Old Row
+}
+
+// Plan — итог пробного прогона импорта.
+type Plan struct {
+	Total     int
+	Add       []Row // новые дни и дополнение пустых полей
+	Conflicts []Conflict
+	Same      int // уже есть в дневнике в точности
+	Problems  []Problem
+}
+
+var hmRx = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+
+// Check проверяет и нормализует строки: дата YYYY-MM-DD не позже today,
+// оценка 0–10, симптомы из списка пользователя catalog, ужин HH:MM, без
+// повторов дней в файле. existing — уже записанные дни (в раскладке Rows),
+// с ними сверяются значения.
+func Check(l i18n.Lang, rows []Row, existing map[string]Row, catalog []models.Symptom, today string) Plan {
+	p := Plan{Total: len(rows)}
+	seen := map[string]int{}
+
+	for i, r := range rows {
+		n := i + 1
+		r.Day = strings.TrimSpace(r.Day)
+		r.Complaints = strings.TrimSpace(r.Complaints)
+		r.Score = strings.TrimSpace(r.Score)
+		r.Dinner = strings.TrimSpace(r.Dinner)
+
+		if _, err := time.Parse("2006-01-02", r.Day); err != nil {
+			p.Problems = append(p.Problems, Problem{n, l.T("import.bad_day", r.Day)})
+			continue
+		}
+		if r.Day > today {
+			p.Problems = append(p.Problems, Problem{n, l.T("import.future_day", r.Day)})
+			continue
+		}
+		if r.Score != "" {
+			v, err := strconv.Atoi(r.Score)
+			if err != nil || v < 0 || v > models.MaxScore {
+				p.Problems = append(p.Problems, Problem{n, l.T("import.bad_score", r.Score, models.MaxScore)})
+				continue
+			}
+			r.Score = strconv.Itoa(v)
+		}
+		symptoms, bad := normalizeSymptoms(r.Symptoms, catalog)
+		if bad != "" {
+			p.Problems = append(p.Problems, Problem{n, l.T("import.bad_symptom", bad)})
+			continue
+		}
+		r.Symptoms = symptoms
+		if r.Dinner != "" {
+			hm, ok := normalizeHM(r.Dinner)
+			if !ok {
+				p.Problems = append(p.Problems, Problem{n, l.T("import.bad_dinner", r.Dinner)})
+				continue
+			}
+			r.Dinner = hm
+		}
+		if r.Complaints == "" && r.Score == "" && r.Symptoms == "" && r.Dinner == "" {
+			p.Problems = append(p.Problems, Problem{n, l.T("import.empty_row")})
+			continue
+		}
+		if first, ok := seen[r.Day]; ok {
+			p.Problems = append(p.Problems, Problem{n, l.T("import.duplicate", r.Day, first)})
+			continue
+		}
+		seen[r.Day] = n
+
+		old, ok := existing[r.Day]
+		switch {
+		case !ok:
+			p.Add = append(p.Add, r)
+		case differs(old.Complaints, r.Complaints) || differs(old.Score, r.Score) ||
+			differs(old.Symptoms, r.Symptoms) || differs(old.Dinner, r.Dinner):
+			p.Conflicts = append(p.Conflicts, Conflict{N: n, New: r, Old: old})
+		case (old.Complaints == "" && r.Complaints != "") || (old.Score == "" && r.Score != "") ||
+			(old.Symptoms == "" && r.Symptoms != "") || (old.Dinner == "" && r.Dinner != ""):
+			p.Add = append(p.Add, r)
+		default:
+			p.Same++
+		}
+	}
+	return p
+}
+
+// differs — оба значения заданы и не совпадают; пустое поле не конфликтует.
+func differs(old, new string) bool {
+	return old != "" && new != "" && old != new
+}
+
+// normalizeSymptoms приводит список симптомов к порядку catalog; bad —
+// первый ключ, которого в catalog нет
+func normalizeSymptoms(s string, catalog []models.Symptom) (list, bad string) {
+	picked := map[string]bool{}
+	for _, k := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			continue
+		}
+		if !slices.ContainsFunc(catalog, func(s models.Symptom) bool { return s.Key == k }) {
+			return "", k
+		}
+		picked[k] = true
+	}
+	var res []string
+	for _, s := range catalog {
+		if picked[s.Key] {
+			res = append(res, s.Key)
+		}
+	}
+	return strings.Join(res, ","), ""
+}
+
+func normalizeHM(s string) (string, bool) {
+	m := hmRx.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	if h > 23 || min > 59 {
+		return "", false
+	}
+	return fmt.Sprintf("%02d:%02d", h, min), true
+}
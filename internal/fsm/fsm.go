@@ -0,0 +1,247 @@
+// Package fsm — диалоги с пользователем как конечные автоматы.
+//
+// Каждый сценарий (Flow) объявляет шаги: что спросить при входе, как
+// разобрать ответ текстом, куда перейти после него и по каким кнопкам.
+// Состояние диалога — models.Conversation — хранится в Store целиком,
+// поэтому переживает перезапуск бота. Telegram движок не знает: вопросы
+// задают хуки, так что сценарии проверяются и без бота.
+package fsm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"telegram-health-dairy/internal/models"
+)
+
+// Особые цели переходов.
+const (
+	End   = "end"   // сценарий выполнен: Flow.Finish
+	Abort = "abort" // сценарий отменён: Flow.Cancel
+)
+
+// ErrStale — кнопка не от текущего шага диалога или диалог уже закончился.
+var ErrStale = errors.New("fsm: диалог уже завершён или сменился")
+
+// Store — где живут диалоги; nil — диалога нет.
+type Store interface {
+	GetConversation(chatID int64) (*models.Conversation, error)
+	SetConversation(chatID int64, c *models.Conversation) error
+}
+
+// Event — что прислал пользователь.
+type Event struct {
+	ChatID  int64
+	MsgID   int    // сообщение с ответом или с нажатой кнопкой; 0 — нет
+	Text    string // ответ текстом
+	Button  string // действие нажатой кнопки
+	Payload string // данные нажатой кнопки
+	Photo   string // file_id присланного фото; Text тогда — подпись
+}
+
+// Hook — действие сценария: задать вопрос, сохранить ответ.
+type Hook func(ev Event, c *models.Conversation) error
+
+// Step — шаг сценария.
+type Step struct {
+	// Enter вызывается при входе на шаг.
+	Enter Hook
+	// Input принимает ответ текстом и кладёт нужное в c.Data. Ошибка —
+	// ответ не принят, шаг не меняется. nil — шаг ждёт только кнопки.
+	Input Hook
+	// Next — куда перейти после принятого текста.
+	Next string
+	// Buttons — куда перейти по кнопке. Нажатая кнопка запоминается
+	// в c.Data под именем шага.
+	Buttons map[string]string
+	// Press вызывается при нажатии кнопки до перехода: кладёт данные кнопки
+	// в c.Data. Ошибка — кнопка не принята, шаг не меняется.
+	Press Hook
+}
+
+// Flow — сценарий диалога.
+type Flow struct {
+	Name    string
+	First   string
+	Steps   map[string]Step
+	Timeout time.Duration // сколько ждать ответа на шаге; 0 — без ограничения
+	Finish  Hook          // после перехода в End
+	Cancel  Hook          // после Abort и /cancel
+	Expire  Hook          // пользователь ответил, когда шаг уже истёк
+}
+
+// Engine ведёт диалоги по объявленным сценариям.
+type Engine struct {
+	store Store
+	flows map[string]*Flow
+	now   func() time.Time
+}
+
+// New проверяет сценарии: переходы должны вести на существующие шаги.
+func New(store Store, now func() time.Time, flows ...*Flow) (*Engine, error) {
+	e := &Engine{store: store, flows: make(map[string]*Flow, len(flows)), now: now}
+	for _, f := range flows {
+		if _, ok := f.Steps[f.First]; !ok {
+			return nil, fmt.Errorf("fsm: %s: нет первого шага %q", f.Name, f.First)
+		}
+		for name, s := range f.Steps {
+			targets := []string{}
+			if s.Input != nil {
+				targets = append(targets, s.Next)
+			}
+			for _, to := range s.Buttons {
+				targets = append(targets, to)
+			}
+			for _, to := range targets {
+				if _, ok := f.Steps[to]; !ok && to != End && to != Abort {
+					return nil, fmt.Errorf("fsm: %s.%s: переход на неизвестный шаг %q", f.Name, name, to)
+				}
+			}
+		}
+		e.flows[f.Name] = f
+	}
+	return e, nil
+}
+
+// Start начинает сценарий с шага step ("" — с первого); текущий диалог,
+// если он был, просто забывается. key — о чём диалог: кнопки шагов
+// принимаются, только если несут тот же key.
+func (e *Engine) Start(chatID int64, flow, step, key string, data map[string]string) error {
+	f, ok := e.flows[flow]
+	if !ok {
+		return fmt.Errorf("fsm: неизвестный сценарий %q", flow)
+	}
+	if step == "" {
+		step = f.First
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	c := &models.Conversation{Flow: flow, Key: key, Data: data}
+	return e.enter(Event{ChatID: chatID}, f, c, step)
+}
+
+// Current — текущий диалог; nil — его нет или он истёк.
+func (e *Engine) Current(chatID int64) (*models.Conversation, error) {
+	c, _, err := e.load(chatID)
+	if c != nil && e.expired(c) {
+		return nil, err
+	}
+	return c, err
+}
+
+// Text передаёт шагу ответ текстом. false — текст не для диалога: диалога
+// нет или шаг ждёт кнопку. Ошибка Input возвращается как есть.
+func (e *Engine) Text(ev Event) (bool, error) {
+	c, f, err := e.load(ev.ChatID)
+	if c == nil || err != nil {
+		return false, err
+	}
+	if e.expired(c) {
+		if err := e.store.SetConversation(ev.ChatID, nil); err != nil {
+			return true, err
+		}
+		return true, call(f.Expire, ev, c)
+	}
+	s := f.Steps[c.Step]
+	if s.Input == nil {
+		return false, nil
+	}
+	if err := s.Input(ev, c); err != nil {
+		return true, err
+	}
+	return true, e.enter(ev, f, c, s.Next)
+}
+
+// Press передаёт шагу нажатую кнопку ev.Button; key — из данных кнопки.
+// ErrStale — кнопка не к текущему шагу этого диалога.
+func (e *Engine) Press(ev Event, key string) error {
+	c, f, err := e.load(ev.ChatID)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.Key != key || e.expired(c) {
+		return ErrStale
+	}
+	next, ok := f.Steps[c.Step].Buttons[ev.Button]
+	if !ok {
+		return ErrStale
+	}
+	if err := call(f.Steps[c.Step].Press, ev, c); err != nil {
+		return err
+	}
+	c.Data[c.Step] = ev.Button
+	return e.enter(ev, f, c, next)
+}
+
+// Cancel прерывает текущий диалог; false — прерывать нечего.
+func (e *Engine) Cancel(ev Event) (bool, error) {
+	c, f, err := e.load(ev.ChatID)
+	if c == nil || err != nil {
+		return false, err
+	}
+	if err := e.store.SetConversation(ev.ChatID, nil); err != nil {
+		return false, err
+	}
+	if e.expired(c) {
+		return false, nil
+	}
+	return true, call(f.Cancel, ev, c)
+}
+
+// enter переводит диалог на шаг to и сохраняет его до вызова хуков:
+// хук может сам начать другой сценарий.
+func (e *Engine) enter(ev Event, f *Flow, c *models.Conversation, to string) error {
+	switch to {
+	case End, Abort:
+		if err := e.store.SetConversation(ev.ChatID, nil); err != nil {
+			return err
+		}
+		if to == End {
+			return call(f.Finish, ev, c)
+		}
+		return call(f.Cancel, ev, c)
+	}
+
+	c.Step = to
+	c.Expires = 0
+	if f.Timeout > 0 {
+		c.Expires = e.now().Add(f.Timeout).Unix()
+	}
+	if err := e.store.SetConversation(ev.ChatID, c); err != nil {
+		return err
+	}
+	return call(f.Steps[to].Enter, ev, c)
+}
+
+// load читает диалог. Диалог сценария, которого больше нет (или с
+// удалённым шагом), считается законченным.
+func (e *Engine) load(chatID int64) (*models.Conversation, *Flow, error) {
+	c, err := e.store.GetConversation(chatID)
+	if c == nil || err != nil {
+		return nil, nil, err
+	}
+	f, ok := e.flows[c.Flow]
+	if ok {
+		_, ok = f.Steps[c.Step]
+	}
+	if !ok {
+		return nil, nil, e.store.SetConversation(chatID, nil)
+	}
+	if c.Data == nil {
+		c.Data = map[string]string{}
+	}
+	return c, f, nil
+}
+
+func (e *Engine) expired(c *models.Conversation) bool {
+	return c.Expires != 0 && e.now().Unix() >= c.Expires
+}
+
+func call(h Hook, ev Event, c *models.Conversation) error {
+	if h == nil {
+		return nil
+	}
+	return h(ev, c)
+}
@@ -0,0 +1,282 @@
+package fsm_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/models"
+)
+
+const chatID = 42
+
+// memStore хранит диалоги в JSON, как база: движок не может менять
+// сохранённое в обход SetConversation
+type memStore map[int64][]byte
+
+func (m memStore) GetConversation(chatID int64) (*models.Conversation, error) {
+	raw, ok := m[chatID]
+	if !ok {
+		return nil, nil
+	}
+	var c models.Conversation
+	err := json.Unmarshal(raw, &c)
+	return &c, err
+}
+
+func (m memStore) SetConversation(chatID int64, c *models.Conversation) error {
+	if c == nil {
+		delete(m, chatID)
+		return nil
+	}
+	raw, err := json.Marshal(c)
+	m[chatID] = raw
+	return err
+}
+
+var errNotNumber = errors.New("не число")
+
+// recorder — сценарий «вес»: число текстом, потом кнопка ok или no
+type recorder struct {
+	log []string
+}
+
+func (r *recorder) hook(name string) fsm.Hook {
+	return func(ev fsm.Event, c *models.Conversation) error {
+		r.log = append(r.log, name)
+		return nil
+	}
+}
+
+func (r *recorder) flow() *fsm.Flow {
+	return &fsm.Flow{
+		Name:    "weight",
+		First:   "ask",
+		Timeout: time.Hour,
+		Steps: map[string]fsm.Step{
+			"ask": {
+				Enter: r.hook("ask"),
+				Input: func(ev fsm.Event, c *models.Conversation) error {
+					if strings.Trim(ev.Text, "0123456789") != "" {
+						return errNotNumber
+					}
+					c.Data["kg"] = ev.Text
+					return nil
+				},
+				Next: "confirm",
+			},
+			"confirm": {
+				Enter:   r.hook("confirm"),
+				Buttons: map[string]string{"ok": fsm.End, "no": fsm.Abort},
+				Press: func(ev fsm.Event, c *models.Conversation) error {
+					c.Data["payload"] = ev.Payload
+					return nil
+				},
+			},
+		},
+		Finish: func(ev fsm.Event, c *models.Conversation) error {
+			r.log = append(r.log, "finish "+c.Data["kg"]+" "+c.Data["confirm"]+" "+c.Data["payload"])
+			return nil
+		},
+		Cancel: r.hook("cancel"),
+		Expire: r.hook("expire"),
+	}
+}
+
+type env struct {
+	t     *testing.T
+	store memStore
+	rec   *recorder
+	now   time.Time
+	e     *fsm.Engine
+}
+
+func newEnv(t *testing.T) *env {
+	v := &env{t: t, store: memStore{}, rec: &recorder{}, now: time.Unix(1_700_000_000, 0)}
+	v.restart()
+	return v
+}
+
+// restart — новый движок на том же хранилище, как после перезапуска бота
+func (v *env) restart() {
+	e, err := fsm.New(v.store, func() time.Time { return v.now }, v.rec.flow())
+	if err != nil {
+		v.t.Fatalf("fsm.New: %v", err)
+	}
+	v.e = e
+}
+
+func (v *env) step() string {
+	c, err := v.e.Current(chatID)
+	if err != nil {
+		v.t.Fatalf("Current: %v", err)
+	}
+	if c == nil {
+		return ""
+	}
+	return c.Step
+}
+
+func (v *env) text(s string) (bool, error) {
+	return v.e.Text(fsm.Event{ChatID: chatID, Text: s})
+}
+
+func (v *env) press(button, key string) error {
+	return v.e.Press(fsm.Event{ChatID: chatID, MsgID: 7, Button: button, Payload: "p"}, key)
+}
+
+func (v *env) wantLog(want ...string) {
+	v.t.Helper()
+	if strings.Join(v.rec.log, "|") != strings.Join(want, "|") {
+		v.t.Fatalf("хуки = %q; want %q", v.rec.log, want)
+	}
+}
+
+func TestFlow(t *testing.T) {
+	v := newEnv(t)
+	if err := v.e.Start(chatID, "weight", "", "d1", nil); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if v.step() != "ask" {
+		t.Fatalf("шаг = %q; want ask", v.step())
+	}
+
+	// неверный ответ: ошибка Input, шаг тот же, вопрос не повторяется
+	ok, err := v.text("много")
+	if !ok || !errors.Is(err, errNotNumber) {
+		t.Fatalf("Text(неверный) = %v, %v; want true, errNotNumber", ok, err)
+	}
+	if v.step() != "ask" {
+		t.Fatalf("шаг после неверного ответа = %q; want ask", v.step())
+	}
+
+	if ok, err := v.text("70"); !ok || err != nil {
+		t.Fatalf("Text = %v, %v", ok, err)
+	}
+	// на шаге с кнопками текст не для диалога
+	if ok, err := v.text("71"); ok || err != nil {
+		t.Fatalf("Text на шаге с кнопками = %v, %v; want false, nil", ok, err)
+	}
+	if err := v.press("ok", "d1"); err != nil {
+		t.Fatalf("Press: %v", err)
+	}
+	if v.step() != "" {
+		t.Fatalf("после End диалог на шаге %q", v.step())
+	}
+	v.wantLog("ask", "confirm", "finish 70 ok p")
+}
+
+func TestStale(t *testing.T) {
+	v := newEnv(t)
+	if err := v.press("ok", "d1"); !errors.Is(err, fsm.ErrStale) {
+		t.Fatalf("Press без диалога = %v; want ErrStale", err)
+	}
+
+	v.e.Start(chatID, "weight", "confirm", "d1", nil)
+	if err := v.press("ok", "d2"); !errors.Is(err, fsm.ErrStale) {
+		t.Errorf("Press с чужим key = %v; want ErrStale", err)
+	}
+	if err := v.press("other", "d1"); !errors.Is(err, fsm.ErrStale) {
+		t.Errorf("Press чужой кнопки = %v; want ErrStale", err)
+	}
+	if v.step() != "confirm" {
+		t.Errorf("шаг после чужих кнопок = %q; want confirm", v.step())
+	}
+
+	// кнопка ещё раз после завершения
+	if err := v.press("ok", "d1"); err != nil {
+		t.Fatalf("Press: %v", err)
+	}
+	if err := v.press("ok", "d1"); !errors.Is(err, fsm.ErrStale) {
+		t.Errorf("повторный Press = %v; want ErrStale", err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	v := newEnv(t)
+	ev := fsm.Event{ChatID: chatID}
+	if ok, err := v.e.Cancel(ev); ok || err != nil {
+		t.Fatalf("Cancel без диалога = %v, %v; want false, nil", ok, err)
+	}
+
+	v.e.Start(chatID, "weight", "", "d1", nil)
+	if ok, err := v.e.Cancel(ev); !ok || err != nil {
+		t.Fatalf("Cancel = %v, %v; want true, nil", ok, err)
+	}
+	if v.step() != "" {
+		t.Fatalf("после Cancel диалог на шаге %q", v.step())
+	}
+
+	// Abort по кнопке тоже вызывает Cancel
+	v.e.Start(chatID, "weight", "confirm", "d1", nil)
+	if err := v.press("no", "d1"); err != nil {
+		t.Fatalf("Press: %v", err)
+	}
+	v.wantLog("ask", "cancel", "confirm", "cancel")
+}
+
+func TestExpire(t *testing.T) {
+	v := newEnv(t)
+	v.e.Start(chatID, "weight", "", "d1", nil)
+	v.now = v.now.Add(time.Hour)
+
+	if v.step() != "" {
+		t.Fatalf("истёкший диалог на шаге %q", v.step())
+	}
+	ok, err := v.text("70")
+	if !ok || err != nil {
+		t.Fatalf("Text после таймаута = %v, %v; want true, nil", ok, err)
+	}
+	v.wantLog("ask", "expire")
+	if _, saved := v.store[chatID]; saved {
+		t.Error("истёкший диалог остался в хранилище")
+	}
+
+	// кнопка истёкшего шага устарела, Cancel прерывать нечего
+	v.e.Start(chatID, "weight", "confirm", "d1", nil)
+	v.now = v.now.Add(time.Hour)
+	if err := v.press("ok", "d1"); !errors.Is(err, fsm.ErrStale) {
+		t.Errorf("Press после таймаута = %v; want ErrStale", err)
+	}
+	if ok, err := v.e.Cancel(fsm.Event{ChatID: chatID}); ok || err != nil {
+		t.Errorf("Cancel после таймаута = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	v := newEnv(t)
+	v.e.Start(chatID, "weight", "", "d1", map[string]string{"note": "x"})
+	v.text("70")
+
+	v.restart()
+	if v.step() != "confirm" {
+		t.Fatalf("шаг после перезапуска = %q; want confirm", v.step())
+	}
+	if err := v.press("ok", "d1"); err != nil {
+		t.Fatalf("Press после перезапуска: %v", err)
+	}
+	v.wantLog("ask", "confirm", "finish 70 ok p")
+
+	// диалог сценария, которого больше нет, забывается при чтении
+	v.store.SetConversation(chatID, &models.Conversation{Flow: "gone", Step: "ask"})
+	if v.step() != "" {
+		t.Fatalf("диалог удалённого сценария на шаге %q", v.step())
+	}
+	if _, saved := v.store[chatID]; saved {
+		t.Error("диалог удалённого сценария остался в хранилище")
+	}
+}
+
+func TestNewChecksTargets(t *testing.T) {
+	f := &fsm.Flow{
+		Name:  "bad",
+		First: "a",
+		Steps: map[string]fsm.Step{"a": {Buttons: map[string]string{"x": "missing"}}},
+	}
+	if _, err := fsm.New(memStore{}, time.Now, f); err == nil {
+		t.Error("fsm.New пропустил переход на неизвестный шаг")
+	}
+}
@@ -1,146 +1,217 @@
 package handlers
 
 import (
-	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/charts"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/messages"
 	"telegram-health-dairy/internal/models"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-const (
-	btnAteNow = "Поел"
-	btnAteAt  = "Поел в …"
-	btnChange = "Изменить"
-	btnYes    = "Да"
-	btnCancel = "Отмена"
-)
-
-// одна на весь пакет
-var confirmKB = tgbotapi.NewInlineKeyboardMarkup(
-	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData(btnYes, "cmp_yes"),
-		tgbotapi.NewInlineKeyboardButtonData(btnCancel, "cmp_cancel"),
-	),
-)
+// yesCancelKB — «Да / Отмена» для подтверждения жалоб и ужина по вопросу dateKey
+func yesCancelKB(l i18n.Lang, yes, cancel callback.Action, dateKey string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("btn.yes"), yes, dateKey, ""),
+			callback.Button(l.T("btn.cancel"), cancel, dateKey, ""),
+		),
+	)
+}
 
-// Клавиатура для вечернего вопроса
-var eveningKB = tgbotapi.NewInlineKeyboardMarkup(
-	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData(btnAteNow, btnAteNow),
-		tgbotapi.NewInlineKeyboardButtonData(btnAteAt, btnAteAt),
-	),
-)
+// callbackRoute обрабатывает нажатие кнопки; false — кнопка устарела
+type callbackRoute func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool
 
-func (h *Handler) HandleCallback(cq *tgbotapi.CallbackQuery) {
-	chatID := cq.Message.Chat.ID
-	data := cq.Data
-	dateKey := extractDateKey(cq.Message.Time(), data)
+// chatRoute — кнопке нужен только чат
+func chatRoute(f func(h *Handler, chatID int64)) callbackRoute {
+	return func(h *Handler, cq *tgbotapi.CallbackQuery, _ callback.Data) bool {
+		f(h, cq.Message.Chat.ID)
+		return true
+	}
+}
 
-	// always answer callback
-	_, _ = h.Bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+// dateRoute — кнопка относится к вопросу, дата в data обязательна
+func dateRoute(f func(h *Handler, chatID int64, dateKey string)) callbackRoute {
+	return func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+		if d.DateKey == "" {
+			return false
+		}
+		f(h, cq.Message.Chat.ID, d.DateKey)
+		return true
+	}
+}
 
-	switch {
-	case data == cbCfgConfirm:
-		h.handleConfirmSettings(chatID)
-	case data == cbCfgChange:
-		h.handleChangeSettings(chatID)
-	case data == btnAteNow:
-		h.handleAteNow(chatID, dateKey)
-	case data == btnAteAt:
-		h.handleAteAt(chatID, dateKey)
-	case data == btnYes:
-		h.handleYes(chatID, cq.Message)
-	case data == btnCancel:
-		h.handleCancel(chatID)
+// msgRoute — кнопка меняет своё сообщение
+func msgRoute(f func(h *Handler, chatID int64, msgID int, d callback.Data)) callbackRoute {
+	return func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+		f(h, cq.Message.Chat.ID, cq.Message.MessageID, d)
+		return true
 	}
 }
 
-func (h *Handler) handleYes(chatID int64, msg *tgbotapi.Message) {
-	// callback.Message.ReplyToMessage содержит исходный текст пользователя
-	userText := msg.ReplyToMessage.Text
-	dateKey := strings.TrimPrefix(h.mustUserState(chatID), "confirm_complaints:")
-	h.DB.UpsertDayRecord(chatID, dateKey[:10], userText)
-	h.DB.DeletePending(chatID, dateKey)
-	h.DB.SetSessionState(chatID, models.StateIdle)
-	h.pushDayKeyboard(chatID)
-	h.DB.SetUserState(chatID, "")
+var callbackRoutes = map[callback.Action]callbackRoute{
+	callback.CfgConfirm: chatRoute((*Handler).handleConfirmSettings),
+	callback.CfgChange:  chatRoute((*Handler).handleChangeSettings),
+	callback.CfgRemind: chatRoute(func(h *Handler, chatID int64) {
+		h.startFlow(chatID, flowReminders, "", "", nil)
+	}),
+	callback.CfgCancel: func(h *Handler, cq *tgbotapi.CallbackQuery, _ callback.Data) bool {
+		h.Bot.Send(tgbotapi.NewDeleteMessage(cq.Message.Chat.ID, cq.Message.MessageID))
+		return true
+	},
+
+	callback.AteNow: dateRoute((*Handler).handleAteNow),
+	callback.AteAt:  dateRoute((*Handler).handleAteAt),
+
+	callback.Snooze: func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+		return d.DateKey != "" && h.handleSnooze(cq.Message.Chat.ID, d.DateKey, d.Payload)
+	},
+	callback.SkipToday: func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+		if d.DateKey == "" {
+			return false
+		}
+		h.handleSkipToday(cq.Message.Chat.ID, cq.Message.MessageID, d.DateKey)
+		return true
+	},
+
+	callback.CheckNone:    checkInRoute,
+	callback.CheckScore:   checkInRoute,
+	callback.CheckSymptom: checkInRoute,
+	callback.CheckNote:    checkInRoute,
+	callback.CheckDone:    checkInRoute,
+	callback.DinnerYes:    flowRoute,
+	callback.DinnerCancel: flowRoute,
+	callback.DinnerTag:    flowRoute,
+	callback.DinnerTagsOK: flowRoute,
+
+	callback.MealKind:   flowRoute,
+	callback.MealNow:    flowRoute,
+	callback.MealSkip:   flowRoute,
+	callback.MealTag:    flowRoute,
+	callback.MealDone:   flowRoute,
+	callback.MealDay:    msgRoute((*Handler).handleMealsCallback),
+	callback.MealDelete: msgRoute((*Handler).handleMealsCallback),
+	callback.MealAdd:    dateRoute((*Handler).askMeal),
+	callback.MealPhotos: dateRoute((*Handler).sendMealPhotos),
+
+	callback.MissedFill: dateRoute((*Handler).handleMissedFill),
+	callback.MissedSkip: func(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+		h.handleMissedSkip(cq.Message, d.DateKey)
+		return true
+	},
+
+	callback.Stats: msgRoute(func(h *Handler, chatID int64, msgID int, d callback.Data) {
+		h.handleStatsPeriod(chatID, msgID, d.Payload)
+	}),
+	callback.Triggers: chatRoute((*Handler).handleTriggers),
+	callback.Chart: msgRoute(func(h *Handler, chatID int64, _ int, d callback.Data) {
+		h.handleChart(chatID, charts.Kind(d.Payload))
+	}),
+
+	callback.HistMonth: msgRoute((*Handler).handleHistoryCallback),
+	callback.HistDay:   msgRoute((*Handler).handleHistoryCallback),
+	callback.HistEditM: msgRoute((*Handler).handleHistoryCallback),
+	callback.HistEditE: msgRoute((*Handler).handleHistoryCallback),
+	callback.HistNop:   msgRoute((*Handler).handleHistoryCallback),
+
+	callback.SymAdd:     msgRoute((*Handler).handleSymptomsCallback),
+	callback.SymRename:  msgRoute((*Handler).handleSymptomsCallback),
+	callback.SymUp:      msgRoute((*Handler).handleSymptomsCallback),
+	callback.SymArchive: msgRoute((*Handler).handleSymptomsCallback),
+	callback.SymRestore: msgRoute((*Handler).handleSymptomsCallback),
+	callback.SymFreq:    msgRoute((*Handler).handleSymptomsCallback),
+
+	callback.ExportRange:  msgRoute((*Handler).handleExportCallback),
+	callback.ExportCustom: msgRoute((*Handler).handleExportCallback),
+	callback.ExportFormat: msgRoute((*Handler).handleExportCallback),
+
+	callback.ImportApply:   flowRoute,
+	callback.ImportReplace: flowRoute,
+	callback.ImportCancel:  flowRoute,
+
+	callback.Lang: msgRoute(func(h *Handler, chatID int64, msgID int, d callback.Data) {
+		h.handleLanguageSet(chatID, msgID, d.Payload)
+	}),
+}
 
-	// благодарим
-	h.Bot.Send(tgbotapi.NewMessage(chatID, "Спасибо — записал!"))
+// legacyAte — кнопки вечернего вопроса до callback.Version. Даты в них нет,
+// поэтому день берём из времени сообщения в поясе пользователя.
+var legacyAte = map[string]callback.Action{
+	"ate_now":  callback.AteNow,
+	"ate_at":   callback.AteAt,
+	"Поел":     callback.AteNow,
+	"Поел в …": callback.AteAt,
 }
 
-func (h *Handler) handleCancel(chatID int64) {
-	dateKey := strings.TrimPrefix(h.mustUserState(chatID), "confirm_complaints:")
-	// просим ввести текст заново
-	h.DB.SetUserState(chatID, "wait_complaints:"+dateKey)
-	h.Bot.Send(tgbotapi.NewMessage(chatID,
-		"Хорошо, введите состояние ещё раз текстом"))
+func (h *Handler) HandleCallback(cq *tgbotapi.CallbackQuery) {
+	chatID := cq.Message.Chat.ID
+
+	d, err := callback.Decode(cq.Data)
+	if a, ok := legacyAte[cq.Data]; err != nil && ok {
+		day := cq.Message.Time().In(h.userLocation(chatID)).Format("2006-01-02")
+		d, err = callback.Data{Action: a, DateKey: day + "-evening"}, nil
+	}
+	route, ok := callbackRoutes[d.Action]
+	if err != nil || !ok || !route(h, cq, d) {
+		h.staleButton(cq)
+		return
+	}
+	_, _ = h.Bot.Request(tgbotapi.NewCallback(cq.ID, ""))
 }
 
-func (h *Handler) mustUserState(chatID int64) string {
-	st, _ := h.DB.GetUserState(chatID)
-	return st
+// staleButton — кнопка из старой версии или вопрос уже закрыт: говорим об
+// этом и убираем кнопки, чтобы на них не нажимали снова
+func (h *Handler) staleButton(cq *tgbotapi.CallbackQuery) {
+	chatID := cq.Message.Chat.ID
+	_, _ = h.Bot.Request(tgbotapi.NewCallback(cq.ID, h.lang(chatID).T("callback.stale")))
+	h.dropKeyboard(chatID, cq.Message.MessageID)
+}
+
+// dropKeyboard убирает inline-кнопки сообщения
+func (h *Handler) dropKeyboard(chatID int64, msgID int) {
+	// nil-клавиатуру Telegram не примет, нужен []
+	h.Bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, msgID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
 }
 
 func (h *Handler) handleConfirmSettings(chatID int64) {
 	u, _ := h.DB.GetUser(chatID)
-	newState := calcCurrentState(u)
+	now := h.clock.Now().In(h.userLocation(chatID))
+	newState := calcCurrentState(u, now)
 	h.DB.SetSessionState(chatID, newState)
 	h.pushDayKeyboard(chatID)
 
-	today := time.Now().In(time.UTC).Format("2006-01-02") // дата-ключ
+	today := now.Format("2006-01-02") // дата-ключ
 
-	h.send(chatID, "Настройки сохранены!")
+	h.sendT(chatID, "settings.saved")
 
 	switch newState {
 	case models.StateWaitingMorning:
-		// шлём вопрос «Жалобы / Нет жалоб»
-		dateKey := today + "-morning"
-		msg := tgbotapi.NewMessage(chatID, "Доброе утро! Опишите своё самочувствие")
-		sent, _ := h.Bot.Send(msg)
-
-		// записываем pending + 0 reminded_at
-		h.DB.InsertPending(&models.PendingMessage{
-			ChatID:  chatID,
-			DateKey: dateKey,
-			Type:    "morning",
-			MsgID:   sent.MessageID,
-		})
-
+		messages.SendMorning(h.Bot, h.DB, u, today+"-morning", now)
 	case models.StateWaitingEvening:
-		dateKey := today + "-evening"
-		hrsLeft := 23 - time.Now().Hour()
-		txt := "Пора ужинать, до конца дня осталось " + strconv.Itoa(hrsLeft) + " ч."
-		msg := tgbotapi.NewMessage(chatID, txt)
-		msg.ReplyMarkup = eveningKB
-		sent, _ := h.Bot.Send(msg)
-
-		h.DB.InsertPending(&models.PendingMessage{
-			ChatID:  chatID,
-			DateKey: dateKey,
-			Type:    "evening",
-			MsgID:   sent.MessageID,
-		})
+		messages.SendEvening(h.Bot, h.DB, u, today+"-evening", now)
 	}
 
-	h.showDebugAllPeriods(chatID, u, newState)
+	h.showDebugAllPeriods(chatID, u, newState, now)
 }
 
-func (h *Handler) showDebugAllPeriods(chatID int64, u *models.User, newState models.State) {
+func (h *Handler) showDebugAllPeriods(chatID int64, u *models.User, newState models.State, now time.Time) {
+	loc, _ := tzToLocation(u.TZ) // IANA или +03:00
+	nowLocal := now.In(loc)
+
 	// helper: HH:MM → time.Time, привязанный к сегодняшней дате в loc
 	parseHM := func(hm string, loc *time.Location) time.Time {
 		t, _ := time.ParseInLocation("15:04", hm, loc)
-		now := time.Now().In(loc)
-		return time.Date(now.Year(), now.Month(), now.Day(),
+		return time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(),
 			t.Hour(), t.Minute(), 0, 0, loc)
 	}
 
-	loc, _ := tzToLocation(u.TZ) // IANA или +03:00
-	nowLocal := time.Now().In(loc)
-
 	morningStart := parseHM(u.MorningAt, loc)
 	morningEnd := morningStart.Add(2 * time.Hour)
 	eveningStart := parseHM(u.EveningAt, loc)
@@ -152,66 +223,117 @@ func (h *Handler) showDebugAllPeriods(chatID int64, u *models.User, newState mod
 
 	switch {
 	case nowLocal.Before(morningStart):
-		nextName = "утреннее окно"
+		nextName = "debug.morning"
 		nextIn = morningStart.Sub(nowLocal)
 	case nowLocal.Before(morningEnd):
-		nextName = "конец утреннего окна"
+		nextName = "debug.morning_end"
 		nextIn = morningEnd.Sub(nowLocal)
 	case nowLocal.Before(eveningStart):
-		nextName = "вечернее окно"
+		nextName = "debug.evening"
 		nextIn = eveningStart.Sub(nowLocal)
 	case nowLocal.Before(eveningEnd):
-		nextName = "конец вечернего окна"
+		nextName = "debug.evening_end"
 		nextIn = eveningEnd.Sub(nowLocal)
 	default:
 		// уже после eveningEnd — следующее утро завтра
-		nextName = "завтрашнее утро"
+		nextName = "debug.tomorrow"
 		nextIn = morningStart.Add(24 * time.Hour).Sub(nowLocal)
 	}
 
-	debug := fmt.Sprintf(
-		"Текущий стейт: %s\n"+
-			"UTC: %s\n"+
-			"Локальное время: %s (%s)\n\n"+
-			"Окно \"утро\": %s — %s\n"+
-			"Окно \"вечер\": %s — %s\n\n"+
-			"След. событие: %s (через %v)",
+	l := i18n.Of(u.Lang)
+	debug := l.T("debug.periods",
 		newState,
-		time.Now().UTC().Format("15:04:05"),
+		now.UTC().Format("15:04:05"),
 		nowLocal.Format("15:04:05"), u.TZ,
 		morningStart.Format("15:04"), morningEnd.Format("15:04"),
 		eveningStart.Format("15:04"), eveningEnd.Format("15:04"),
-		nextName, nextIn.Round(time.Minute),
+		l.T(nextName), nextIn.Round(time.Minute),
 	)
 
 	h.send(chatID, debug)
 }
 
 func (h *Handler) handleChangeSettings(chatID int64) {
-	h.DB.SetUserState(chatID, "setup_morning")
-	h.send(chatID, "Введите время утреннего сообщения HH:MM")
+	h.startFlow(chatID, flowSetup, "", "", nil)
 }
 
+// handleAteNow — «Поел» записывает ужином текущий момент, поэтому
+// принимается только под сегодняшним вечерним вопросом. Кнопка
+// прошлого дня спрашивает время ужина, как «Поел в …».
 func (h *Handler) handleAteNow(chatID int64, dateKey string) {
-	h.DB.SetDinner(chatID, dateKey[:10], time.Now())
+	now := h.clock.Now()
+	if dateKey != now.In(h.userLocation(chatID)).Format("2006-01-02")+"-evening" {
+		h.handleAteAt(chatID, dateKey)
+		return
+	}
+	h.DB.SetDinner(chatID, dateKey[:10], now)
+	h.resolvePending(chatID, dateKey)
+	h.sendT(chatID, "dinner.enjoy")
+	h.askDinnerTags(chatID, dateKey)
+}
+
+// resolvePending снимает вопрос после ответа. Если это был активный вопрос,
+// сессия возвращается в idle; ответ задним числом (пропущенный день)
+// текущий вопрос не трогает.
+func (h *Handler) resolvePending(chatID int64, dateKey string) {
+	if !h.DB.HasPending(chatID, dateKey) {
+		return
+	}
 	h.DB.DeletePending(chatID, dateKey)
 	h.DB.SetSessionState(chatID, models.StateIdle)
 	h.pushDayKeyboard(chatID)
-	h.send(chatID, "Приятного вечера!")
+}
+
+// handleSnooze откладывает напоминания о вопросе на payload минут;
+// false — вопрос уже закрыт и кнопка устарела
+func (h *Handler) handleSnooze(chatID int64, dateKey, payload string) bool {
+	minutes, err := strconv.Atoi(payload)
+	if err != nil || !slices.Contains(messages.SnoozeOptions, minutes) {
+		return false
+	}
+	until := h.clock.Now().Add(time.Duration(minutes) * time.Minute)
+	ok, err := h.DB.SnoozePending(chatID, dateKey, until)
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return true
+	}
+	if !ok {
+		return false
+	}
+	h.sendT(chatID, "prompt.snooze_ok", until.In(h.userLocation(chatID)).Format("15:04"))
+	return true
+}
+
+// handleSkipToday — пользователь сам решил не отвечать на вопрос. Отметка
+// skipped, в отличие от missed, не предлагает заполнить его задним числом.
+func (h *Handler) handleSkipToday(chatID int64, msgID int, dateKey string) {
+	l := h.lang(chatID)
+	label := messages.PromptLabel(l, dateKey)
+	if h.DB.HasAnswered(chatID, dateKey) {
+		h.send(chatID, l.T("missed.filled", label))
+		return
+	}
+	if err := h.DB.MarkPrompt(chatID, dateKey, models.MarkSkipped); err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	h.resolvePending(chatID, dateKey)
+	h.dropKeyboard(chatID, msgID)
+	h.send(chatID, l.T("prompt.skipped", label))
 }
 
 func (h *Handler) handleAteAt(chatID int64, dateKey string) {
-	h.DB.SetUserState(chatID, "wait_dinner:"+dateKey)
-	h.send(chatID, "Введите время ужина HH:MM")
+	h.askDinner(chatID, dateKey)
 }
 
 // внутри handlers/callbacks.go или рядом
-func calcCurrentState(u *models.User) models.State {
+// now — текущий момент; окна считаются в часовом поясе пользователя
+func calcCurrentState(u *models.User, now time.Time) models.State {
 	if u == nil { // ← 1. защита от nil
 		return models.StateNotStarted //   или Idle — как удобнее
 	}
 	loc, _ := tzToLocation(u.TZ) // IANA или +03:00 → *time.Location
-	now := time.Now().In(loc)
+	now = now.In(loc)
 
 	parse := func(hm string) time.Time {
 		t, _ := time.ParseInLocation("15:04", hm, loc)
@@ -236,17 +358,76 @@ func calcCurrentState(u *models.User) models.State {
 	}
 }
 
-func extractDateKey(t time.Time, data string) string {
-	day := t.UTC().Format("2006-01-02")
+// handleMissedFill — заполнить задним числом вопрос, который бот не задал
+func (h *Handler) handleMissedFill(chatID int64, dateKey string) {
+	if h.DB.HasAnswered(chatID, dateKey) {
+		l := h.lang(chatID)
+		h.send(chatID, l.T("missed.filled", messages.PromptLabel(l, dateKey)))
+		return
+	}
+
+	if strings.HasSuffix(dateKey, "-evening") {
+		h.askDinner(chatID, dateKey)
+		return
+	}
+	h.askCheckIn(chatID, dateKey)
+}
+
+// handleMissedSkip — пользователь сознательно не будет заполнять
+// пропущенный вопрос dateKey: его кнопки убираются из сообщения msg.
+// dateKey "" — «Пропустить все»: все пропущенные вопросы без ответа.
+func (h *Handler) handleMissedSkip(msg *tgbotapi.Message, dateKey string) {
+	chatID, msgID := msg.Chat.ID, msg.MessageID
+	if dateKey != "" {
+		if !h.DB.HasAnswered(chatID, dateKey) {
+			h.DB.MarkPrompt(chatID, dateKey, models.MarkSkipped)
+		}
+		h.dropMissedRow(msg, dateKey)
+		return
+	}
 
-	// всё, что связано с ужином, считаем evening
-	switch data {
-	case btnAteNow, btnAteAt: // «Поел» / «Поел в …»
-		return day + "-evening"
-	case "cmp_dinner_yes", "cmp_dinner_cancel":
-		return day + "-evening"
+	marks, _ := h.DB.ListPromptMarks(chatID, models.MarkMissed)
+	n := 0
+	for _, m := range marks {
+		if h.DB.HasAnswered(chatID, m.DateKey) {
+			continue
+		}
+		h.DB.MarkPrompt(chatID, m.DateKey, models.MarkSkipped)
+		n++
 	}
 
-	// остальное относится к утру (жалобы, cmp_yes/cancel)
-	return day + "-morning"
+	h.dropKeyboard(chatID, msgID) // кнопки больше не нужны
+	h.send(chatID, h.lang(chatID).N("missed.skipped", n))
+}
+
+// dropMissedRow убирает из клавиатуры msg кнопки вопроса dateKey; когда
+// заполнять больше нечего, клавиатура убирается целиком
+func (h *Handler) dropMissedRow(msg *tgbotapi.Message, dateKey string) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	left := 0
+	if msg.ReplyMarkup != nil {
+		for _, row := range msg.ReplyMarkup.InlineKeyboard {
+			var keep []tgbotapi.InlineKeyboardButton
+			for _, b := range row {
+				if b.CallbackData != nil {
+					d, err := callback.Decode(*b.CallbackData)
+					if err == nil && d.DateKey == dateKey {
+						continue
+					}
+					if err == nil && d.Action == callback.MissedFill {
+						left++
+					}
+				}
+				keep = append(keep, b)
+			}
+			if len(keep) > 0 {
+				rows = append(rows, keep)
+			}
+		}
+	}
+	if left == 0 {
+		h.dropKeyboard(msg.Chat.ID, msg.MessageID)
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageReplyMarkup(msg.Chat.ID, msg.MessageID, tgbotapi.NewInlineKeyboardMarkup(rows...)))
 }
@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/models"
+)
+
+// register — /start и подтверждение настроек по умолчанию
+func (e *e2e) register() {
+	e.t.Helper()
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, "/start"))
+	settings := e.await(n, "настройки", func(s bot.Sent) bool { return button(s, callback.CfgConfirm) != "" })
+	e.f.Inject(e.f.Press(chatID, settings.MsgID, button(settings, callback.CfgConfirm)))
+	e.awaitText(n, ru.T("settings.saved"))
+	e.awaitState(models.StateIdle)
+}
+
+// TestAteNowPreviousDay — «Поел» под вчерашним вопросом не записывает
+// вчерашним ужином сегодняшнее время, а спрашивает, когда был ужин
+func TestAteNowPreviousDay(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("нет tzdata: %v", err)
+	}
+	now := time.Date(2025, 5, 8, 20, 0, 0, 0, msk)
+	e := newE2E(t, now)
+	e.register()
+
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Press(chatID, 1, callback.Encode(callback.AteNow, "2025-05-07-evening", "")))
+	e.awaitText(n, strings.Split(ru.T("ask.dinner"), "(")[0])
+	if rec, _ := e.db.GetDayRecord(chatID, "2025-05-07"); rec != nil && rec.DinnerAt != nil {
+		t.Errorf("ужин 7 мая = %v; want не записан", rec.DinnerAt)
+	}
+	e.f.Inject(e.f.Text(chatID, "/cancel"))
+
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Press(chatID, 1, callback.Encode(callback.AteNow, "2025-05-08-evening", "")))
+	e.awaitText(n, ru.T("dinner.enjoy"))
+	rec, err := e.db.GetDayRecord(chatID, "2025-05-08")
+	if err != nil || rec == nil || rec.DinnerAt == nil || !rec.DinnerAt.Equal(now) {
+		t.Errorf("ужин 8 мая = %+v, %v; want %v", rec, err, now)
+	}
+}
+
+// TestExportCustomRange — свой период экспорта: неверный и будущий период
+// переспрашиваются, верный выгружается
+func TestExportCustomRange(t *testing.T) {
+	e := newE2E(t, time.Date(2025, 5, 8, 12, 0, 0, 0, time.UTC))
+	e.register()
+	e.db.UpsertDayRecord(chatID, "2025-05-01", "нет")
+	e.db.UpsertDayRecord(chatID, "2025-05-06", "нет")
+	e.db.UpsertDayRecord(chatID, "2025-05-08", "нет")
+
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, "/export"))
+	ask := e.await(n, "выбор периода", func(s bot.Sent) bool { return button(s, callback.ExportCustom) != "" })
+	e.f.Inject(e.f.Press(chatID, ask.MsgID, button(ask, callback.ExportCustom)))
+	e.awaitText(n, ru.T("export.ask_dates"))
+
+	for text, hint := range map[string]string{
+		"с мая":                   "export.bad_dates",
+		"2025-05-06 - 2025-05-01": "export.bad_dates",
+		"2025-05-07–2025-05-09":   "export.future_dates",
+	} {
+		n = len(e.f.Sent())
+		e.f.Inject(e.f.Text(chatID, text))
+		e.awaitText(n, ru.T(hint))
+	}
+
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, "2025-05-01–2025-05-06"))
+	formats := e.await(n, "выбор формата", func(s bot.Sent) bool { return button(s, callback.ExportFormat) != "" })
+	e.f.Inject(e.f.Press(chatID, formats.MsgID, button(formats, callback.ExportFormat)))
+	doc := e.await(n, "файл", func(s bot.Sent) bool { return s.Method == "sendDocument" })
+	if want := ru.T("export.caption", "2025-05-01", "2025-05-06", 2); doc.Text != want {
+		t.Errorf("подпись = %q; want %q", doc.Text, want)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/charts"
+	"telegram-health-dairy/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleCharts(chatID int64) {
+	l := h.lang(chatID)
+	msg := tgbotapi.NewMessage(chatID, l.T("charts.ask"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(chartsRow(l))
+	h.Bot.Send(msg)
+}
+
+func chartsRow(l i18n.Lang) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, k := range charts.Kinds {
+		row = append(row, callback.Button(charts.Title(l, k), callback.Chart, "", string(k)))
+	}
+	return row
+}
+
+// handleChart присылает выбранный график картинкой
+func (h *Handler) handleChart(chatID int64, kind charts.Kind) {
+	days, ok := charts.Days[kind]
+	if !ok {
+		return
+	}
+
+	l := h.lang(chatID)
+	now := h.clock.Now().In(h.userLocation(chatID))
+	from := now.AddDate(0, 0, -days+1).Format("2006-01-02")
+	recs, err := h.DB.ListDayRecords(chatID, from, now.Format("2006-01-02"))
+	if err != nil {
+		h.send(chatID, l.T("charts.error", err))
+		return
+	}
+	png, err := charts.Render(l, kind, recs, now)
+	if err != nil {
+		h.send(chatID, l.T("charts.error", err))
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: string(kind) + ".png", Bytes: png})
+	photo.Caption = charts.Title(l, kind)
+	h.Bot.Send(photo)
+}
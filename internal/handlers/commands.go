@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"fmt"
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/i18n"
 	"telegram-health-dairy/internal/models"
 	"telegram-health-dairy/internal/utils"
 	"time"
@@ -9,71 +11,65 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-const (
-	menuStats   = "Показать статистику"
-	menuMorning = "Задать время утреннего сообщения"
-	menuEvening = "Задать время вечернего сообщения"
-	menuTZ      = "Сменить часовой пояс"
-	menuClear   = "Очистить данные"
-)
-
-const (
-	cbCfgConfirm = "cfg_confirm"
-	cbCfgChange  = "cfg_change"
-)
-
 func (h *Handler) HandleCommand(msg *tgbotapi.Message) {
 	chatID := msg.Chat.ID
 	cmd := msg.Command()
 	st, _ := h.DB.GetSessionState(chatID)
+	l := h.msgLang(msg)
 
-	if cmd == "reset_all" {
+	// /reset_all удаляет базу целиком, поэтому доступен только админу
+	if cmd == "reset_all" && h.admin != 0 && chatID == h.admin {
 		if err := h.DB.DropAll(); err != nil {
-			h.send(chatID, "Ошибка: "+err.Error())
+			h.send(chatID, l.T("error", err))
 		} else {
-			h.send(chatID, "База удалена, перезапустите бот")
+			h.send(chatID, l.T("reset.done"))
 		}
+		return
 	}
 
 	if !validateInitialState(st, cmd) {
 		if st == models.StateInitial {
-			h.send(chatID, "Перед тем как продолжить работу с ботом, подтвердите настройки")
+			h.send(chatID, l.T("initial.confirm"))
 		}
 		return
 	}
 
 	switch cmd {
 	case "start":
-		h.handleStart(chatID)
+		h.handleStart(chatID, l)
 	case "current_state":
-		h.handleCurrentState(chatID)
+		h.handleCurrentState(chatID, l)
 	case "settings":
 		h.handleSettings(chatID)
+	case "stats":
+		h.handleStats(chatID)
+	case "correlation":
+		h.handleCorrelation(chatID)
+	case "charts":
+		h.handleCharts(chatID)
+	case "history":
+		h.handleHistory(chatID)
+	case "symptoms":
+		h.handleSymptoms(chatID)
+	case "meal":
+		h.handleMeal(chatID)
+	case "export":
+		h.handleExport(chatID)
+	case "import":
+		h.handleImport(chatID)
+	case "report":
+		h.handleReport(chatID, msg.CommandArguments())
+	case "language":
+		h.handleLanguage(chatID)
+	case "cancel":
+		h.handleCancel(chatID)
 	case "help":
-		h.send(chatID, "/start — начать\n/help — справка")
-	default:
-		// main menu buttons
-		switch msg.Text {
-		case menuStats:
-			h.send(chatID, "Статистика пока не реализована")
-		case menuMorning:
-			_ = h.DB.SetUserState(chatID, "setup_morning")
-			h.send(chatID, "Введите время утреннего сообщения HH:MM")
-		case menuEvening:
-			_ = h.DB.SetUserState(chatID, "setup_evening")
-			h.send(chatID, "Введите время вечернего сообщения HH:MM")
-		case menuTZ:
-			_ = h.DB.SetUserState(chatID, "setup_timezone")
-			h.send(chatID, "Введите часовой пояс, например Europe/Moscow")
-		case menuClear:
-			_ = h.DB.ClearData(chatID)
-			h.send(chatID, "Данные очищены")
-		}
+		h.send(chatID, l.T("help"))
 	}
 }
 
-func (h *Handler) handleStart(chatID int64) {
-	err := h.ensureUser(chatID)
+func (h *Handler) handleStart(chatID int64, l i18n.Lang) {
+	err := h.ensureUser(chatID, l)
 	utils.LogFor(err)
 
 	st, err := h.DB.GetSessionState(chatID)
@@ -86,53 +82,52 @@ func (h *Handler) handleStart(chatID int64) {
 		h.askConfirmDefaults(chatID)
 	} else {
 		// 2. Бот уже запущен и НЕ в Initial-flow  → просто сообщаем состояние.
-		txt := fmt.Sprintf(
-			"Бот уже запущен.\n"+
-				"Текущий стейт: %s\n\n"+
-				"Если хотите изменить настройки, отправьте /settings",
-			st,
-		)
-		h.send(chatID, txt)
+		h.send(chatID, l.T("start.running", st))
 	}
 }
 
 // helpers
-func (h *Handler) ensureUser(chatID int64) error {
+func (h *Handler) ensureUser(chatID int64, l i18n.Lang) error {
 	user, _ := h.DB.GetUser(chatID)
 
 	if user == nil {
-		return h.DB.UpsertUser(&models.User{
+		err := h.DB.UpsertUser(&models.User{
 			ChatID:    chatID,
 			TZ:        "Europe/Moscow",
 			MorningAt: "10:00",
 			EveningAt: "18:00",
+			Lang:      string(l),
+
+			RemindEvery: models.DefaultRemindEvery,
+			RemindMax:   models.DefaultRemindMax,
 		})
+		if err != nil {
+			return err
+		}
+		h.sched.Reschedule(chatID)
 	}
 	return nil
 }
 
-func (h *Handler) handleCurrentState(chatID int64) {
-	_ = h.ensureUser(chatID)
+func (h *Handler) handleCurrentState(chatID int64, l i18n.Lang) {
+	_ = h.ensureUser(chatID, l)
 	u, _ := h.DB.GetUser(chatID)
-	state := calcCurrentState(u)
-	msg := "Текущий статус: " + string(state)
-	h.send(chatID, msg)
+	state := calcCurrentState(u, h.clock.Now())
+	h.send(chatID, i18n.Of(u.Lang).T("state.current", state))
 }
 
 func (h *Handler) handleSettings(chatID int64) {
 	u, _ := h.DB.GetUser(chatID)
-	tzDisplay := gmtString(u.TZ)
+	l := i18n.Of(u.Lang)
 
-	text := fmt.Sprintf(
-		"Текущие настройки:\nУтро: %s\nВечер: %s\nЧасовой пояс: %s",
-		u.MorningAt, u.EveningAt, tzDisplay,
-	)
-
-	msg := tgbotapi.NewMessage(chatID, text)
+	msg := tgbotapi.NewMessage(chatID, h.settingsText(l, u))
 	kb := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Изменить", cbCfgChange),
-			tgbotapi.NewInlineKeyboardButtonData("Отмена", btnCancel),
+			callback.Button(l.T("btn.change"), callback.CfgChange, "", ""),
+			callback.Button(l.T("btn.cancel"), callback.CfgCancel, "", ""),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("btn.reminders"), callback.CfgRemind, "", ""),
 		),
 	)
 
@@ -144,29 +139,70 @@ func (h *Handler) send(chatID int64, text string) {
 	h.Bot.Send(tgbotapi.NewMessage(chatID, text))
 }
 
-func (h *Handler) askConfirmDefaults(chatID int64) {
+// sendT — send с текстом из каталога на языке пользователя
+func (h *Handler) sendT(chatID int64, key string, args ...any) {
+	h.send(chatID, h.lang(chatID).T(key, args...))
+}
+
+// lang — язык интерфейса пользователя; до /start — язык по умолчанию
+func (h *Handler) lang(chatID int64) i18n.Lang {
 	u, _ := h.DB.GetUser(chatID)
-	tzDisplay := gmtString(u.TZ)
+	if u == nil {
+		return i18n.Default
+	}
+	return i18n.Of(u.Lang)
+}
 
-	text := fmt.Sprintf(
-		"Текущие настройки:\nУтро: %s\nВечер: %s\nЧасовой пояс: %s",
-		u.MorningAt, u.EveningAt, tzDisplay,
-	)
+// msgLang — как lang, но пока пользователя нет, берём язык из профиля Telegram
+func (h *Handler) msgLang(msg *tgbotapi.Message) i18n.Lang {
+	if u, _ := h.DB.GetUser(msg.Chat.ID); u != nil {
+		return i18n.Of(u.Lang)
+	}
+	if msg.From != nil {
+		return i18n.FromTelegram(msg.From.LanguageCode)
+	}
+	return i18n.Default
+}
+
+func (h *Handler) askConfirmDefaults(chatID int64) {
+	u, _ := h.DB.GetUser(chatID)
+	l := i18n.Of(u.Lang)
 
-	msg := tgbotapi.NewMessage(chatID, text)
+	msg := tgbotapi.NewMessage(chatID, h.settingsText(l, u))
 	kb := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Подтвердить", cbCfgConfirm),
-			tgbotapi.NewInlineKeyboardButtonData("Изменить", cbCfgChange),
+			callback.Button(l.T("btn.confirm"), callback.CfgConfirm, "", ""),
+			callback.Button(l.T("btn.change"), callback.CfgChange, "", ""),
 		),
 	)
 	msg.ReplyMarkup = kb
 	h.Bot.Send(msg)
 }
 
+// settingsText — расписание вопросов и политика напоминаний
+func (h *Handler) settingsText(l i18n.Lang, u *models.User) string {
+	tzDisplay := gmtString(u.TZ, h.clock.Now())
+	return l.T("settings.current", u.MorningAt, u.EveningAt, tzDisplay) + "\n" + reminderText(l, u)
+}
+
+func reminderText(l i18n.Lang, u *models.User) string {
+	policy := l.T("remind.off")
+	if u.RemindMax > 0 {
+		policy = l.T("remind.policy", u.RemindEvery, l.N("times", u.RemindMax))
+	}
+	return l.T("settings.remind", policy, quietText(l, u))
+}
+
+func quietText(l i18n.Lang, u *models.User) string {
+	if u.QuietFrom == "" {
+		return l.T("quiet.off")
+	}
+	return u.QuietFrom + "–" + u.QuietTo
+}
+
 func validateInitialState(st models.State, cmd string) bool {
 	isInitialState := (st == models.StateNotStarted) || (st == models.StateInitial)
-	isAvailableForAll := cmd == "start" || cmd == "help" || cmd == "current_state"
+	isAvailableForAll := cmd == "start" || cmd == "help" || cmd == "current_state" || cmd == "cancel"
 
 	if isInitialState && !isAvailableForAll {
 		return false
@@ -175,13 +211,14 @@ func validateInitialState(st models.State, cmd string) bool {
 	}
 }
 
-func gmtString(tz string) string {
+// gmtString — смещение пояса tz в момент now (летом и зимой оно разное)
+func gmtString(tz string, now time.Time) string {
 	loc, err := tzToLocation(tz)
 	if err != nil || loc == nil { // fallback, чтобы не паниковать
 		return "GMT"
 	}
 
-	_, off := time.Now().In(loc).Zone()
+	_, off := now.In(loc).Zone()
 	if off == 0 {
 		return "GMT"
 	}
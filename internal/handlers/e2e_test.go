@@ -0,0 +1,257 @@
+package handlers_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/clock"
+	"telegram-health-dairy/internal/config"
+	"telegram-health-dairy/internal/handlers"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/messages"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const chatID = 42
+
+var ru = i18n.Of("ru")
+
+// e2e — бот целиком: обработчики, планировщик и SQLite, Telegram — bot.Fake,
+// время — clock.Fake
+type e2e struct {
+	t   *testing.T
+	f   *bot.Fake
+	clk *clock.Fake
+	db  *storage.DB
+}
+
+func newE2E(t *testing.T, start time.Time) *e2e {
+	t.Helper()
+	clk := clock.NewFake(start)
+	db, err := storage.Open(storage.DriverSQLite, filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatalf("storage.Open: %v", err)
+	}
+	db.SetClock(clk)
+
+	f := bot.NewFake()
+	f.Now = clk.Now
+	ctx, cancel := context.WithCancel(context.Background())
+	h, err := handlers.Register(ctx, f, db, clk, config.Config{UpdateMode: config.ModePolling})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		if err := h.Shutdown(5 * time.Second); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+		f.Close()
+		db.Close()
+	})
+	return &e2e{t: t, f: f, clk: clk, db: db}
+}
+
+// await ждёт после from отправленное сообщение, для которого ok
+func (e *e2e) await(from int, what string, ok func(s bot.Sent) bool) bot.Sent {
+	e.t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		sent := e.f.Sent()
+		for _, s := range sent[min(from, len(sent)):] {
+			if ok(s) {
+				return s
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	e.t.Fatalf("не дождались: %s; отправлено: %+v", what, e.f.Sent()[from:])
+	return bot.Sent{}
+}
+
+// awaitText ждёт сообщение, начинающееся с text
+func (e *e2e) awaitText(from int, text string) bot.Sent {
+	e.t.Helper()
+	return e.await(from, text, func(s bot.Sent) bool {
+		return s.Method == "sendMessage" && strings.HasPrefix(s.Text, text)
+	})
+}
+
+// awaitState ждёт состояния сессии: бот меняет его уже после отправки
+// сообщения
+func (e *e2e) awaitState(want models.State) {
+	e.t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if st, _ := e.db.GetSessionState(chatID); st == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	st, _ := e.db.GetSessionState(chatID)
+	e.t.Fatalf("state = %s; want %s", st, want)
+}
+
+// advanceTo двигает часы поминутно до to: так таймеры планировщика и
+// gocron срабатывают по очереди, как в реальном времени
+func (e *e2e) advanceTo(to time.Time) {
+	for e.clk.Now().Before(to) {
+		e.clk.Advance(time.Minute)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func button(s bot.Sent, a callback.Action) string {
+	for _, row := range s.Buttons() {
+		for _, data := range row {
+			if d, err := callback.Decode(data); err == nil && d.Action == a {
+				return data
+			}
+		}
+	}
+	return ""
+}
+
+// TestDay — первый день пользователя: /start, подтверждение настроек,
+// утренний вопрос и чек-ин, вечерний вопрос и время ужина.
+func TestDay(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("нет tzdata: %v", err)
+	}
+	e := newE2E(t, time.Date(2025, 5, 8, 9, 0, 0, 0, msk))
+
+	// /start → настройки по умолчанию с кнопкой подтверждения
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, "/start"))
+	settings := e.await(n, "настройки", func(s bot.Sent) bool { return button(s, callback.CfgConfirm) != "" })
+	if !strings.Contains(settings.Text, "10:00") || !strings.Contains(settings.Text, "18:00") {
+		t.Errorf("настройки = %q; want 10:00 и 18:00", settings.Text)
+	}
+
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Press(chatID, settings.MsgID, button(settings, callback.CfgConfirm)))
+	e.awaitText(n, ru.T("settings.saved"))
+	e.awaitState(models.StateIdle)
+
+	// 10:00 — утренний вопрос
+	n = len(e.f.Sent())
+	e.advanceTo(time.Date(2025, 5, 8, 10, 0, 0, 0, msk))
+	morning := e.awaitText(n, ru.T("prompt.morning"))
+	e.awaitState(models.StateWaitingMorning)
+
+	// чек-ин кнопками под вопросом: оценка, симптом, «Готово»
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Press(chatID, morning.MsgID, callback.Encode(callback.CheckScore, "2025-05-08-morning", "3")))
+	e.await(n, "кнопки с оценкой", func(s bot.Sent) bool {
+		return s.Method == "editMessageReplyMarkup" && s.MsgID == morning.MsgID
+	})
+	e.f.Inject(e.f.Press(chatID, morning.MsgID, callback.Encode(callback.CheckSymptom, "2025-05-08-morning", "heartburn")))
+	e.f.Inject(e.f.Press(chatID, morning.MsgID, callback.Encode(callback.CheckDone, "2025-05-08-morning", "")))
+	saved := e.awaitText(n, strings.TrimSuffix(ru.T("checkin.saved"), "%s"))
+	if !strings.Contains(saved.Text, "3/10") || !strings.Contains(saved.Text, ru.T("symptom.heartburn")) {
+		t.Errorf("чек-ин = %q; want оценку 3/10 и изжогу", saved.Text)
+	}
+	rec, err := e.db.GetDayRecord(chatID, "2025-05-08")
+	if err != nil || rec == nil || rec.CheckIn == nil || rec.CheckIn.Score == nil || *rec.CheckIn.Score != 3 {
+		t.Fatalf("запись дня = %+v, %v; want чек-ин с оценкой 3", rec, err)
+	}
+	e.awaitState(models.StateIdle)
+
+	// 18:00 — вечерний вопрос, «Поел в …» и время ужина
+	n = len(e.f.Sent())
+	e.advanceTo(time.Date(2025, 5, 8, 18, 0, 0, 0, msk))
+	evening := e.await(n, "вечерний вопрос", func(s bot.Sent) bool { return button(s, callback.AteAt) != "" })
+	e.awaitState(models.StateWaitingEvening)
+
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Press(chatID, evening.MsgID, button(evening, callback.AteAt)))
+	e.awaitText(n, strings.Split(ru.T("ask.dinner"), "(")[0])
+	e.f.Inject(e.f.Text(chatID, "19:30"))
+	confirm := e.await(n, "подтверждение ужина", func(s bot.Sent) bool { return button(s, callback.DinnerYes) != "" })
+	e.f.Inject(e.f.Press(chatID, confirm.MsgID, button(confirm, callback.DinnerYes)))
+	e.awaitText(n, ru.T("dinner.saved"))
+
+	// после ужина бот спрашивает, что ели; закрываем без тегов
+	tags := e.await(n, "теги ужина", func(s bot.Sent) bool { return button(s, callback.DinnerTagsOK) != "" })
+	e.f.Inject(e.f.Press(chatID, tags.MsgID, button(tags, callback.DinnerTagsOK)))
+	e.awaitText(n, ru.T("dinner.tags_none"))
+
+	rec, err = e.db.GetDayRecord(chatID, "2025-05-08")
+	if err != nil || rec == nil || rec.DinnerAt == nil {
+		t.Fatalf("запись дня = %+v, %v; want ужин", rec, err)
+	}
+	if got := rec.DinnerAt.In(msk).Format("15:04"); got != "19:30" {
+		t.Errorf("ужин = %s; want 19:30", got)
+	}
+	e.awaitState(models.StateIdle)
+}
+
+// TestResetAllAdminOnly — без ADMIN_CHAT_ID /reset_all не удаляет базу
+func TestResetAllAdminOnly(t *testing.T) {
+	e := newE2E(t, time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC))
+	e.f.Inject(e.f.Text(chatID, "/start"))
+	e.await(0, "настройки", func(s bot.Sent) bool { return button(s, callback.CfgConfirm) != "" })
+
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, "/reset_all"))
+	e.f.Inject(e.f.Text(chatID, "/current_state"))
+	e.await(n, "ответ на /current_state", func(s bot.Sent) bool { return s.Method == "sendMessage" })
+	for _, s := range e.f.Sent()[n:] {
+		if s.Text == ru.T("reset.done") {
+			t.Fatal("/reset_all выполнен не админом")
+		}
+	}
+	if u, err := e.db.GetUser(chatID); err != nil || u == nil {
+		t.Fatalf("пользователь после /reset_all = %v, %v; want сохранён", u, err)
+	}
+}
+
+// TestMissedSkip — «Пропустить» у пропущенного вопроса закрывает только его
+func TestMissedSkip(t *testing.T) {
+	e := newE2E(t, time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC))
+	e.f.Inject(e.f.Text(chatID, "/start"))
+	e.await(0, "настройки", func(s bot.Sent) bool { return button(s, callback.CfgConfirm) != "" })
+
+	keys := []string{"2025-05-06-evening", "2025-05-07-morning"}
+	for _, key := range keys {
+		e.db.MarkPrompt(chatID, key, models.MarkMissed)
+	}
+	n := len(e.f.Sent())
+	if err := messages.SendMissed(e.f, ru, chatID, keys); err != nil {
+		t.Fatalf("SendMissed: %v", err)
+	}
+	missed := e.f.Sent()[n]
+	kb := missed.Markup.(tgbotapi.InlineKeyboardMarkup)
+
+	n = len(e.f.Sent())
+	upd := e.f.Press(chatID, missed.MsgID, callback.Encode(callback.MissedSkip, keys[0], ""))
+	upd.CallbackQuery.Message.ReplyMarkup = &kb
+	e.f.Inject(upd)
+	edit := e.await(n, "клавиатура без пропущенного", func(s bot.Sent) bool {
+		return s.Method == "editMessageReplyMarkup" && s.MsgID == missed.MsgID
+	})
+	for _, row := range edit.Buttons() {
+		for _, data := range row {
+			if d, _ := callback.Decode(data); d.DateKey == keys[0] {
+				t.Errorf("кнопка %q пропущенного вопроса осталась", data)
+			}
+		}
+	}
+	if button(edit, callback.MissedFill) == "" {
+		t.Error("кнопка второго вопроса пропала")
+	}
+
+	marks, _ := e.db.ListPromptMarks(chatID, models.MarkMissed)
+	if len(marks) != 1 || marks[0].DateKey != keys[1] {
+		t.Errorf("пропущенные после «Пропустить» = %+v; want только %s", marks, keys[1])
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/export"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// периоды в днях, 0 — за всё время
+var exportRanges = []int{7, 30, 90, 0}
+
+// свой период: 2025-05-01–2025-05-31, тире любое
+var exportDatesRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\s*[-–—]\s*(\d{4}-\d{2}-\d{2})$`)
+
+func (h *Handler) handleExport(chatID int64) {
+	l := h.lang(chatID)
+	var row []tgbotapi.InlineKeyboardButton
+	for _, days := range exportRanges {
+		label := l.T("export.all")
+		if days > 0 {
+			label = l.N("days", days)
+		}
+		row = append(row, callback.Button(label, callback.ExportRange, "", strconv.Itoa(days)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, l.T("export.ask_range"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row, tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("export.custom"), callback.ExportCustom, "", "")))
+	h.Bot.Send(msg)
+}
+
+// exportFormats — кнопки форматов; rng — дни или свой период YYYYMMDD-YYYYMMDD
+func exportFormats(rng string) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, f := range export.Formats {
+		row = append(row, callback.Button(
+			strings.ToUpper(string(f)), callback.ExportFormat, "", rng+":"+string(f)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
+
+// handleExportCallback: после периода спрашиваем формат, после формата шлём файл
+// payload: ExportRange — дни, ExportFormat — период:формат
+func (h *Handler) handleExportCallback(chatID int64, msgID int, d callback.Data) {
+	l := h.lang(chatID)
+	switch d.Action {
+	case callback.ExportRange:
+		h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID,
+			l.T("export.ask_format"), exportFormats(d.Payload)))
+		return
+	case callback.ExportCustom:
+		h.dropKeyboard(chatID, msgID)
+		h.startFlow(chatID, flowExport, "", "", nil)
+		return
+	}
+
+	rng, format, ok := strings.Cut(d.Payload, ":")
+	if !ok {
+		return
+	}
+	if err := h.sendExport(chatID, l, rng, export.Format(format)); err != nil {
+		h.send(chatID, l.T("export.error", err))
+	}
+}
+
+// takeExportDates принимает свой период: начало не позже конца, конец не
+// позже сегодняшнего дня пользователя
+func (h *Handler) takeExportDates(ev fsm.Event, c *models.Conversation) error {
+	m := exportDatesRe.FindStringSubmatch(strings.TrimSpace(ev.Text))
+	if m == nil {
+		return invalid("export.bad_dates")
+	}
+	loc := h.userLocation(ev.ChatID)
+	from, err1 := time.ParseInLocation("2006-01-02", m[1], loc)
+	to, err2 := time.ParseInLocation("2006-01-02", m[2], loc)
+	if err1 != nil || err2 != nil || from.After(to) {
+		return invalid("export.bad_dates")
+	}
+	if to.Format("2006-01-02") > h.clock.Now().In(loc).Format("2006-01-02") {
+		return invalid("export.future_dates")
+	}
+	c.Data["range"] = from.Format("20060102") + "-" + to.Format("20060102")
+	return nil
+}
+
+func (h *Handler) askExportFormat(ev fsm.Event, c *models.Conversation) error {
+	msg := tgbotapi.NewMessage(ev.ChatID, h.lang(ev.ChatID).T("export.ask_format"))
+	msg.ReplyMarkup = exportFormats(c.Data["range"])
+	_, err := h.Bot.Send(msg)
+	return err
+}
+
+// exportPeriod — границы выгрузки по rng: дни (0 — всё время) или
+// YYYYMMDD-YYYYMMDD
+func (h *Handler) exportPeriod(u *models.User, rng string) (fromDay, toDay string, err error) {
+	if a, b, custom := strings.Cut(rng, "-"); custom {
+		from, err1 := time.Parse("20060102", a)
+		to, err2 := time.Parse("20060102", b)
+		if err1 != nil || err2 != nil {
+			return "", "", fmt.Errorf("bad range %q", rng)
+		}
+		return from.Format("2006-01-02"), to.Format("2006-01-02"), nil
+	}
+	days, err := strconv.Atoi(rng)
+	if err != nil || days < 0 {
+		return "", "", fmt.Errorf("bad range %q", rng)
+	}
+
+	loc := h.userLocation(u.ChatID)
+	now := h.clock.Now().In(loc)
+	from := h.since(u, loc)
+	if days > 0 {
+		from = now.AddDate(0, 0, -days+1)
+	}
+	return from.Format("2006-01-02"), now.Format("2006-01-02"), nil
+}
+
+func (h *Handler) sendExport(chatID int64, l i18n.Lang, rng string, f export.Format) error {
+	u, err := h.DB.GetUser(chatID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return errors.New(l.T("no_user"))
+	}
+	fromDay, toDay, err := h.exportPeriod(u, rng)
+	if err != nil {
+		return err
+	}
+
+	recs, err := h.DB.ListDayRecords(chatID, fromDay, toDay)
+	if err != nil {
+		return err
+	}
+	if len(recs) == 0 {
+		h.send(chatID, l.T("export.empty"))
+		return nil
+	}
+
+	data, err := export.Encode(f, export.Rows(recs, h.userLocation(chatID)))
+	if err != nil {
+		return err
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("health-diary_%s_%s.%s", fromDay, toDay, f),
+		Bytes: data,
+	})
+	doc.Caption = l.T("export.caption", fromDay, toDay, len(recs))
+	_, err = h.Bot.Send(doc)
+	return err
+}
@@ -0,0 +1,561 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/messages"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Сценарии диалогов (см. fsm). Ключ диалога чек-ина и ужина — вопрос
+// (2025-05-08-morning), импорта — file_unique_id присланного файла,
+// переименования симптома — его ключ, записи о еде — день (2025-05-08),
+// тегов ужина — вечерний вопрос, экспорта — пустой.
+const (
+	flowSetup     = "setup"     // morning → evening → tz
+	flowCheckIn   = "checkin"   // prompt | pick ⇄ note
+	flowDinner    = "dinner"    // wait → confirm
+	flowImport    = "import"    // confirm
+	flowReminders = "reminders" // every → max → quiet
+	flowSymptoms  = "symptoms"  // add | rename
+	flowMeal      = "meal"      // type → when → what → mark
+	flowTags      = "tags"      // mark
+	flowExport    = "export"    // dates
+)
+
+// invalid — ответ не принят; значение — ключ i18n с подсказкой
+type invalid string
+
+func (e invalid) Error() string { return string(e) }
+
+func (h *Handler) newFlows() (*fsm.Engine, error) {
+	return fsm.New(h.DB, h.clock.Now,
+		&fsm.Flow{
+			Name:    flowSetup,
+			First:   "morning",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"morning": {
+					Enter: h.ask("ask.morning_at"),
+					Input: h.setUserTime(func(u *models.User, hm string) { u.MorningAt = hm }),
+					Next:  "evening",
+				},
+				"evening": {
+					Enter: h.ask("ask.evening_at"),
+					Input: h.setUserTime(func(u *models.User, hm string) { u.EveningAt = hm }),
+					Next:  "tz",
+				},
+				"tz": {Enter: h.ask("ask.tz_full"), Input: h.setUserTZ, Next: fsm.End},
+			},
+			Finish: func(ev fsm.Event, _ *models.Conversation) error {
+				h.handleConfirmSettings(ev.ChatID)
+				return nil
+			},
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name: flowCheckIn,
+			// prompt — кнопки под самим утренним вопросом, см. checkInRoute
+			First:   "pick",
+			Timeout: 12 * time.Hour,
+			Steps: map[string]fsm.Step{
+				"prompt": h.checkInStep(nil),
+				"pick":   h.checkInStep(h.showCheckIn),
+				"note":   h.checkInStep(h.ask("checkin.note_ask")),
+			},
+			Finish: h.saveCheckIn,
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name:    flowDinner,
+			First:   "wait",
+			Timeout: 12 * time.Hour,
+			Steps: map[string]fsm.Step{
+				"wait": {Enter: h.askDinnerTime, Input: h.takeDinner, Next: "confirm"},
+				"confirm": {Enter: h.confirmDinner, Buttons: map[string]string{
+					string(callback.DinnerYes):    fsm.End,
+					string(callback.DinnerCancel): "wait",
+				}},
+			},
+			Finish: h.saveDinner,
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name: flowImport,
+			// ссылка на файл в Telegram живёт около часа
+			First:   "confirm",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				// сводку с кнопками показывает handleImportDocument
+				"confirm": {Buttons: map[string]string{
+					string(callback.ImportApply):   fsm.End,
+					string(callback.ImportReplace): fsm.End,
+					string(callback.ImportCancel):  fsm.Abort,
+				}},
+			},
+			Finish: h.applyImport,
+			Cancel: h.importCanceled,
+		},
+		&fsm.Flow{
+			Name:    flowReminders,
+			First:   "every",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"every": {
+					Enter: h.askPolicy("ask.remind_every", func(_ i18n.Lang, u *models.User) any { return u.RemindEvery }),
+					Input: h.setRemindEvery,
+					Next:  "max",
+				},
+				"max": {
+					Enter: h.askPolicy("ask.remind_max", func(_ i18n.Lang, u *models.User) any { return u.RemindMax }),
+					Input: h.setRemindMax,
+					Next:  "quiet",
+				},
+				"quiet": {
+					Enter: h.askPolicy("ask.quiet", func(l i18n.Lang, u *models.User) any { return quietText(l, u) }),
+					Input: h.setQuietHours,
+					Next:  fsm.End,
+				},
+			},
+			Finish: func(ev fsm.Event, _ *models.Conversation) error {
+				u, err := h.DB.GetUser(ev.ChatID)
+				if err != nil || u == nil {
+					return err
+				}
+				l := i18n.Of(u.Lang)
+				h.send(ev.ChatID, l.T("remind.saved", reminderText(l, u)))
+				return nil
+			},
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name:    flowSymptoms,
+			First:   "add",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"add":    {Enter: h.ask("symptoms.ask_add"), Input: h.takeSymptomName, Next: fsm.End},
+				"rename": {Enter: h.askRename, Input: h.takeSymptomName, Next: fsm.End},
+			},
+			Finish: h.saveSymptom,
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name:    flowMeal,
+			First:   "type",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"type": {
+					Enter:   h.askMealKind,
+					Buttons: map[string]string{string(callback.MealKind): "when"},
+					Press:   h.pressMeal,
+				},
+				"when": {
+					Enter:   h.askMealTime,
+					Input:   h.takeMealTime,
+					Next:    "what",
+					Buttons: map[string]string{string(callback.MealNow): "what"},
+					Press:   h.pressMeal,
+				},
+				"what": {
+					Enter:   h.askMealWhat,
+					Input:   h.takeMealWhat,
+					Next:    "mark",
+					Buttons: map[string]string{string(callback.MealSkip): "mark"},
+				},
+				"mark": h.tagStep("mark", callback.MealTag, callback.MealDone, "meal.ask_tags"),
+			},
+			Finish: h.saveMeal,
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name:    flowTags,
+			First:   "mark",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"mark": h.tagStep("mark", callback.DinnerTag, callback.DinnerTagsOK, "dinner.ask_tags"),
+			},
+			Finish: h.saveDinnerTags,
+			Expire: h.flowExpired,
+		},
+		&fsm.Flow{
+			Name:    flowExport,
+			First:   "dates",
+			Timeout: time.Hour,
+			Steps: map[string]fsm.Step{
+				"dates": {Enter: h.ask("export.ask_dates"), Input: h.takeExportDates, Next: fsm.End},
+			},
+			Finish: h.askExportFormat,
+			Expire: h.flowExpired,
+		},
+	)
+}
+
+// startFlow начинает сценарий, прерывая текущий диалог
+func (h *Handler) startFlow(chatID int64, flow, step, key string, data map[string]string) {
+	if err := h.flows.Start(chatID, flow, step, key, data); err != nil {
+		log.Printf("⚠️ fsm: chat %d: %v", chatID, err)
+	}
+}
+
+// flowRoute — кнопка шага диалога. Ключ диалога кнопка несёт в дате
+// (вопрос) или в payload (импорт).
+func flowRoute(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+	key := d.DateKey
+	if key == "" {
+		key = d.Payload
+	}
+	err := h.flows.Press(fsm.Event{
+		ChatID:  cq.Message.Chat.ID,
+		MsgID:   cq.Message.MessageID,
+		Button:  string(d.Action),
+		Payload: d.Payload,
+	}, key)
+	if errors.Is(err, fsm.ErrStale) {
+		return false
+	}
+	var bad invalid
+	if errors.As(err, &bad) {
+		h.sendT(cq.Message.Chat.ID, string(bad))
+	} else if err != nil {
+		log.Printf("⚠️ fsm: chat %d: %v", cq.Message.Chat.ID, err)
+	}
+	return true
+}
+
+// handleFlowText передаёт текст текущему шагу; false — диалога нет
+func (h *Handler) handleFlowText(msg *tgbotapi.Message) bool {
+	ev := fsm.Event{ChatID: msg.Chat.ID, MsgID: msg.MessageID, Text: msg.Text}
+	if n := len(msg.Photo); n > 0 {
+		// размеры фото идут по возрастанию, берём самое крупное
+		ev.Photo, ev.Text = msg.Photo[n-1].FileID, msg.Caption
+	}
+	ok, err := h.flows.Text(ev)
+	var bad invalid
+	if errors.As(err, &bad) {
+		h.sendT(msg.Chat.ID, string(bad))
+	} else if err != nil {
+		log.Printf("⚠️ fsm: chat %d: %v", msg.Chat.ID, err)
+	}
+	return ok
+}
+
+// handleCancel — /cancel: прервать любой диалог
+func (h *Handler) handleCancel(chatID int64) {
+	ok, err := h.flows.Cancel(fsm.Event{ChatID: chatID})
+	switch {
+	case err != nil:
+		h.sendT(chatID, "error", err)
+	case ok:
+		h.sendT(chatID, "cancel.done")
+	default:
+		h.sendT(chatID, "cancel.none")
+	}
+}
+
+func (h *Handler) ask(key string) fsm.Hook {
+	return func(ev fsm.Event, _ *models.Conversation) error {
+		h.sendT(ev.ChatID, key)
+		return nil
+	}
+}
+
+func (h *Handler) flowExpired(ev fsm.Event, _ *models.Conversation) error {
+	h.sendT(ev.ChatID, "flow.expired")
+	return nil
+}
+
+// ---------- setup -----------------------------------------------------------
+
+// setUserTime сохраняет время вопроса сразу: прерванная настройка оставляет
+// то, что уже введено. Время хранится как HH:MM.
+func (h *Handler) setUserTime(set func(u *models.User, hm string)) fsm.Hook {
+	return func(ev fsm.Event, _ *models.Conversation) error {
+		t, err := time.Parse("15:04", strings.TrimSpace(ev.Text))
+		if err != nil {
+			return invalid("bad_hm")
+		}
+		u, err := h.DB.GetUser(ev.ChatID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return invalid("no_user")
+		}
+		set(u, t.Format("15:04"))
+		if err := h.DB.UpsertUser(u); err != nil {
+			return err
+		}
+		h.sched.Reschedule(ev.ChatID)
+		return nil
+	}
+}
+
+func (h *Handler) setUserTZ(ev fsm.Event, _ *models.Conversation) error {
+	tz, err := validateTZ(ev.Text)
+	if err != nil {
+		return invalid("bad_tz")
+	}
+	u, err := h.DB.GetUser(ev.ChatID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return invalid("no_user")
+	}
+	u.TZ = tz
+	if err := h.DB.UpsertUser(u); err != nil {
+		return err
+	}
+	h.sched.Reschedule(ev.ChatID)
+	return nil
+}
+
+// ---------- reminders -------------------------------------------------------
+
+// askPolicy задаёт вопрос key, подставляя текущее значение настройки
+func (h *Handler) askPolicy(key string, current func(l i18n.Lang, u *models.User) any) fsm.Hook {
+	return func(ev fsm.Event, _ *models.Conversation) error {
+		u, err := h.DB.GetUser(ev.ChatID)
+		if err != nil {
+			return err
+		}
+		if u == nil {
+			return invalid("no_user")
+		}
+		l := i18n.Of(u.Lang)
+		h.send(ev.ChatID, l.T(key, current(l, u)))
+		return nil
+	}
+}
+
+func (h *Handler) setRemindEvery(ev fsm.Event, _ *models.Conversation) error {
+	n, err := strconv.Atoi(strings.TrimSpace(ev.Text))
+	if err != nil || n < 5 || n > 120 {
+		return invalid("bad_every")
+	}
+	return h.updateUser(ev.ChatID, func(u *models.User) { u.RemindEvery = n })
+}
+
+func (h *Handler) setRemindMax(ev fsm.Event, _ *models.Conversation) error {
+	n, err := strconv.Atoi(strings.TrimSpace(ev.Text))
+	if err != nil || n < 0 || n > 10 {
+		return invalid("bad_max")
+	}
+	return h.updateUser(ev.ChatID, func(u *models.User) { u.RemindMax = n })
+}
+
+// setQuietHours принимает «23:00-08:00» или «-» (тихих часов нет)
+func (h *Handler) setQuietHours(ev fsm.Event, _ *models.Conversation) error {
+	text := strings.ReplaceAll(strings.TrimSpace(ev.Text), "–", "-")
+	if text == "-" {
+		return h.updateUser(ev.ChatID, func(u *models.User) { u.QuietFrom, u.QuietTo = "", "" })
+	}
+	a, b, ok := strings.Cut(text, "-")
+	if !ok {
+		return invalid("bad_quiet")
+	}
+	from, err1 := time.Parse("15:04", strings.TrimSpace(a))
+	to, err2 := time.Parse("15:04", strings.TrimSpace(b))
+	if err1 != nil || err2 != nil || from.Equal(to) {
+		return invalid("bad_quiet")
+	}
+	return h.updateUser(ev.ChatID, func(u *models.User) {
+		u.QuietFrom, u.QuietTo = from.Format("15:04"), to.Format("15:04")
+	})
+}
+
+// updateUser меняет настройки пользователя; расписание вопросов не трогает
+func (h *Handler) updateUser(chatID int64, set func(u *models.User)) error {
+	u, err := h.DB.GetUser(chatID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return invalid("no_user")
+	}
+	set(u)
+	return h.DB.UpsertUser(u)
+}
+
+// ---------- check-in --------------------------------------------------------
+
+// checkInStep — шаг чек-ина с входом enter. Кнопки на всех шагах одни и те
+// же: оценка и симптомы переключаются, текст можно прислать в любой момент.
+func (h *Handler) checkInStep(enter fsm.Hook) fsm.Step {
+	return fsm.Step{
+		Enter: enter,
+		Input: h.takeCheckInNote,
+		Next:  fsm.End,
+		Buttons: map[string]string{
+			string(callback.CheckNone):    fsm.End,
+			string(callback.CheckScore):   "pick",
+			string(callback.CheckSymptom): "pick",
+			string(callback.CheckNote):    "note",
+			string(callback.CheckDone):    fsm.End,
+		},
+		Press: h.pressCheckIn,
+	}
+}
+
+// checkInRoute — кнопка чек-ина. Под самим утренним вопросом диалога ещё
+// нет: он начинается с первого нажатия, пока вопрос ждёт ответа.
+func checkInRoute(h *Handler, cq *tgbotapi.CallbackQuery, d callback.Data) bool {
+	chatID := cq.Message.Chat.ID
+	if d.DateKey == "" {
+		return false
+	}
+	c, _ := h.flows.Current(chatID)
+	if (c == nil || c.Flow != flowCheckIn || c.Key != d.DateKey) && h.DB.HasPending(chatID, d.DateKey) {
+		h.startFlow(chatID, flowCheckIn, "prompt", d.DateKey, nil)
+	}
+	return flowRoute(h, cq, d)
+}
+
+// pressCheckIn запоминает нажатое в c.Data: score, symptoms (через запятую,
+// в порядке списка пользователя), none, а в msg — сообщение с клавиатурой
+func (h *Handler) pressCheckIn(ev fsm.Event, c *models.Conversation) error {
+	switch callback.Action(ev.Button) {
+	case callback.CheckScore:
+		v, err := strconv.Atoi(ev.Payload)
+		if err != nil || v < 0 || v > models.MaxScore {
+			return fsm.ErrStale
+		}
+		if c.Data["score"] == ev.Payload {
+			delete(c.Data, "score")
+		} else {
+			c.Data["score"] = ev.Payload
+		}
+	case callback.CheckSymptom:
+		catalog, err := h.DB.ListSymptoms(ev.ChatID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(catalog, func(s models.Symptom) bool { return s.Key == ev.Payload && !s.Archived }) {
+			return fsm.ErrStale
+		}
+		picked := strings.Split(c.Data["symptoms"], ",")
+		var res []string
+		for _, s := range catalog {
+			if slices.Contains(picked, s.Key) != (s.Key == ev.Payload) {
+				res = append(res, s.Key)
+			}
+		}
+		c.Data["symptoms"] = strings.Join(res, ",")
+	case callback.CheckNone:
+		c.Data["none"] = "1"
+	case callback.CheckDone:
+		if c.Data["score"] == "" && c.Data["symptoms"] == "" && c.Data["text"] == "" {
+			return invalid("checkin.empty")
+		}
+	}
+	c.Data["msg"] = strconv.Itoa(ev.MsgID)
+	return nil
+}
+
+// checkInData — отмеченное в диалоге
+func checkInData(c *models.Conversation) *models.CheckIn {
+	ck := &models.CheckIn{NoComplaints: c.Data["none"] == "1"}
+	if v, err := strconv.Atoi(c.Data["score"]); err == nil {
+		ck.Score = &v
+	}
+	if c.Data["symptoms"] != "" {
+		ck.Symptoms = strings.Split(c.Data["symptoms"], ",")
+	}
+	return ck
+}
+
+// showCheckIn перерисовывает кнопки под нажатым сообщением, а при старте
+// диалога присылает их новым сообщением
+func (h *Handler) showCheckIn(ev fsm.Event, c *models.Conversation) error {
+	l := h.lang(ev.ChatID)
+	catalog, err := h.DB.ListSymptoms(ev.ChatID)
+	if err != nil {
+		return err
+	}
+	kb := messages.CheckInKB(l, c.Key, catalog, checkInData(c))
+	if ev.Button != "" && ev.MsgID != 0 {
+		_, err := h.Bot.Request(tgbotapi.NewEditMessageReplyMarkup(ev.ChatID, ev.MsgID, kb))
+		return err
+	}
+	msg := tgbotapi.NewMessage(ev.ChatID, l.T("checkin.ask", messages.PromptLabel(l, c.Key)))
+	msg.ReplyMarkup = kb
+	_, err = h.Bot.Send(msg)
+	return err
+}
+
+func (h *Handler) takeCheckInNote(ev fsm.Event, c *models.Conversation) error {
+	c.Data["text"] = strings.TrimSpace(ev.Text)
+	return nil
+}
+
+func (h *Handler) saveCheckIn(ev fsm.Event, c *models.Conversation) error {
+	rec := models.DayRecord{Complaints: c.Data["text"], CheckIn: checkInData(c)}
+	if err := h.DB.SaveCheckIn(ev.ChatID, c.Key[:10], rec.CheckIn, rec.Complaints); err != nil {
+		return err
+	}
+	h.resolvePending(ev.ChatID, c.Key)
+	if id, _ := strconv.Atoi(c.Data["msg"]); id != 0 {
+		h.dropKeyboard(ev.ChatID, id)
+	}
+	l := h.lang(ev.ChatID)
+	catalog, _ := h.DB.ListSymptoms(ev.ChatID)
+	h.send(ev.ChatID, l.T("checkin.saved", stats.Morning(l, rec, catalog)))
+	return nil
+}
+
+// ---------- dinner ----------------------------------------------------------
+
+func (h *Handler) askDinnerTime(ev fsm.Event, c *models.Conversation) error {
+	l := h.lang(ev.ChatID)
+	h.send(ev.ChatID, l.T("ask.dinner", messages.PromptLabel(l, c.Key)))
+	return nil
+}
+
+func (h *Handler) takeDinner(ev fsm.Event, c *models.Conversation) error {
+	if !timeRx.MatchString(ev.Text) {
+		return invalid("bad_hm")
+	}
+	parts := strings.Split(ev.Text, ":")
+	hour, _ := strconv.Atoi(parts[0])
+	min, _ := strconv.Atoi(parts[1])
+	if hour > 23 || min > 59 {
+		return invalid("bad_time")
+	}
+	c.Data["time"] = fmt.Sprintf("%02d:%02d", hour, min)
+	return nil
+}
+
+func (h *Handler) confirmDinner(ev fsm.Event, c *models.Conversation) error {
+	l := h.lang(ev.ChatID)
+	confirm := tgbotapi.NewMessage(ev.ChatID, l.T("dinner.confirm", c.Data["time"], messages.PromptLabel(l, c.Key)))
+	confirm.ReplyMarkup = yesCancelKB(l, callback.DinnerYes, callback.DinnerCancel, c.Key)
+	_, err := h.Bot.Send(confirm)
+	return err
+}
+
+func (h *Handler) saveDinner(ev fsm.Event, c *models.Conversation) error {
+	t, err := time.Parse("15:04", c.Data["time"])
+	if err != nil {
+		return err
+	}
+	dinner := h.dinnerTime(c.Key[:10], t.Hour(), t.Minute(), h.userLocation(ev.ChatID))
+	if err := h.DB.SetDinner(ev.ChatID, c.Key[:10], dinner); err != nil {
+		return err
+	}
+	h.resolvePending(ev.ChatID, c.Key)
+	h.sendT(ev.ChatID, "dinner.saved")
+	h.askDinnerTags(ev.ChatID, c.Key)
+	return nil
+}
@@ -0,0 +1,32 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSetupTime — время вопроса проверяется по часам и минутам и
+// сохраняется как HH:MM: иначе планировщик не сможет его разобрать
+func TestSetupTime(t *testing.T) {
+	e := newE2E(t, time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC))
+	e.register()
+
+	n := len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, ru.T("menu.morning")))
+	e.awaitText(n, ru.T("ask.morning_at"))
+	for _, bad := range []string{"24:00", "7:60", "25:99"} {
+		n = len(e.f.Sent())
+		e.f.Inject(e.f.Text(chatID, bad))
+		e.awaitText(n, ru.T("bad_hm"))
+	}
+	if u, _ := e.db.GetUser(chatID); u == nil || u.MorningAt != "10:00" {
+		t.Fatalf("утро после неверного времени = %+v; want 10:00", u)
+	}
+
+	n = len(e.f.Sent())
+	e.f.Inject(e.f.Text(chatID, " 7:05 "))
+	e.awaitText(n, ru.T("ask.evening_at"))
+	if u, _ := e.db.GetUser(chatID); u == nil || u.MorningAt != "07:05" {
+		t.Errorf("утро = %+v; want 07:05", u)
+	}
+}
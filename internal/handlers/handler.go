@@ -1,64 +1,203 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"sync"
+	"time"
 
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/clock"
+	"telegram-health-dairy/internal/config"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
 	"telegram-health-dairy/internal/models"
 	"telegram-health-dairy/internal/scheduler"
 	"telegram-health-dairy/internal/storage"
-	"telegram-health-dairy/internal/utils"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// ключи i18n кнопок reply-клавиатуры дня
 const (
-	kbYesterdayDinner    = "Вчера ужинал в …"
-	kbTodayMorningStatus = "Самочувствие утром"
-	kbDinner             = "Ужинал в …"
-	kbPrevMorningStatus  = "Самочувствие прошлым утром"
+	kbYesterdayDinner    = "kb.yesterday_dinner"
+	kbTodayMorningStatus = "kb.today_morning"
+	kbDinner             = "kb.dinner"
+	kbPrevMorningStatus  = "kb.prev_morning"
+	kbMeal               = "kb.meal"
 )
 
 type Handler struct {
-	Bot *tgbotapi.BotAPI
-	DB  *storage.DB
+	Bot bot.API
+	DB  storage.Store
+
+	clock clock.Clock
+	ctx   context.Context
+	fail  context.CancelCauseFunc
+	sched *scheduler.Scheduler
+	flows *fsm.Engine
+	srv   *http.Server // nil в режиме long polling
+	admin int64        // config.Config.AdminChatID
+
+	mu       sync.Mutex
+	closing  bool
+	inflight sync.WaitGroup
 }
 
-func Register(bot *tgbotapi.BotAPI, db *storage.DB) {
-	h := &Handler{Bot: bot, DB: db}
-	go h.listen() // background
+// Register запускает планировщик и приём апдейтов. Приём останавливается,
+// когда отменяется ctx; после этого нужно вызвать Shutdown. Время
+// обработчики и планировщик берут из clk.
+func Register(ctx context.Context, api bot.API, db storage.Store, clk clock.Clock, cfg config.Config) (*Handler, error) {
+	h := &Handler{Bot: api, DB: db, clock: clk, admin: cfg.AdminChatID}
+	h.ctx, h.fail = context.WithCancelCause(ctx)
+
+	flows, err := h.newFlows()
+	if err != nil {
+		return nil, err
+	}
+	h.flows = flows
+
+	sched, err := scheduler.Start(api, db, scheduler.Options{
+		Grace:    cfg.SchedulerGrace,
+		Lookback: cfg.MissedLookback,
+		Clock:    clk,
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.sched = sched
 
-	_, err := scheduler.Start(bot, db)
-	utils.LogFor(err)
+	switch cfg.UpdateMode {
+	case config.ModeWebhook:
+		srv, err := h.newWebhookServer(cfg.Webhook)
+		if err != nil {
+			sched.Shutdown()
+			return nil, err
+		}
+		h.srv = srv
+		go func() {
+			if err := h.serveWebhook(cfg.Webhook); !errors.Is(err, http.ErrServerClosed) {
+				h.fail(err)
+			}
+		}()
+	default:
+		go h.listen() // background
+	}
+	return h, nil
 }
 
-func (h *Handler) listen() {
-	go func() {
-		if _, err := scheduler.Start(h.Bot, h.DB); err != nil {
-			log.Fatal(err)
+// Wait блокируется до отмены контекста (сигнал) или падения приёма апдейтов.
+// Во втором случае возвращает причину.
+func (h *Handler) Wait() error {
+	<-h.ctx.Done()
+	if err := context.Cause(h.ctx); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown перестаёт принимать апдейты, дожидается обработчиков, которые уже
+// работают, и останавливает планировщик. Всё вместе — не дольше timeout.
+func (h *Handler) Shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	h.mu.Lock()
+	h.closing = true
+	h.mu.Unlock()
+	h.fail(nil)
+
+	var errs []error
+	if h.srv != nil {
+		if err := h.srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	} else {
+		h.Bot.StopReceivingUpdates()
+	}
+
+	if err := wait(ctx, h.inflight.Wait); err != nil {
+		errs = append(errs, fmt.Errorf("обработчики апдейтов: %w", err))
+	}
+	if err := wait(ctx, func() {
+		if err := h.sched.Shutdown(); err != nil {
+			log.Printf("⚠️ scheduler: %v", err)
 		}
+	}); err != nil {
+		errs = append(errs, fmt.Errorf("планировщик: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// wait выполняет блокирующий fn, но не дольше, чем живёт ctx
+func wait(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
 	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) listen() {
+	// getUpdates не работает, пока у бота зарегистрирован вебхук
+	if _, err := h.Bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("⚠️ deleteWebhook: %v", err)
+	}
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 30
 
 	updates := h.Bot.GetUpdatesChan(u)
 
-	for upd := range updates {
-		switch {
-		case upd.Message != nil:
-			// === 📌 Обработка текстовых сообщений ===
-			h.HandleMessage(upd.Message)
-
-		case upd.CallbackQuery != nil:
-			// === 📌 Обработка callback кнопок ===
-			h.HandleCallback(upd.CallbackQuery)
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case upd, ok := <-updates:
+			if !ok {
+				return
+			}
+			h.dispatch(upd)
 		}
 	}
 }
 
+// dispatch — общая точка входа для long polling и вебхука.
+// Возвращает false, если бот уже останавливается и апдейт не обработан.
+func (h *Handler) dispatch(upd tgbotapi.Update) bool {
+	h.mu.Lock()
+	if h.closing {
+		h.mu.Unlock()
+		return false
+	}
+	h.inflight.Add(1)
+	h.mu.Unlock()
+	defer h.inflight.Done()
+
+	switch {
+	case upd.Message != nil:
+		// === 📌 Обработка текстовых сообщений ===
+		h.HandleMessage(upd.Message)
+
+	case upd.CallbackQuery != nil:
+		// === 📌 Обработка callback кнопок ===
+		h.HandleCallback(upd.CallbackQuery)
+	}
+	return true
+}
+
 func (h *Handler) pushDayKeyboard(chatID int64) {
 	st, _ := h.DB.GetSessionState(chatID)
-	kb := buildDayKeyboard(st)
+	kb := buildDayKeyboard(h.lang(chatID), st)
 
 	cfg := tgbotapi.NewMessage(chatID, "\u2063") // zero-width char
 	cfg.ReplyMarkup = kb
@@ -66,7 +205,7 @@ func (h *Handler) pushDayKeyboard(chatID int64) {
 	_, _ = h.Bot.Send(cfg)
 }
 
-func buildDayKeyboard(st models.State) tgbotapi.ReplyKeyboardMarkup {
+func buildDayKeyboard(l i18n.Lang, st models.State) tgbotapi.ReplyKeyboardMarkup {
 	// пустая (но не nil) — Telegram умеет «прятать» клавиатуру,
 	// если в ней нет кнопок
 	empty := tgbotapi.NewReplyKeyboard()
@@ -75,23 +214,75 @@ func buildDayKeyboard(st models.State) tgbotapi.ReplyKeyboardMarkup {
 	case models.StateWaitingMorning, models.StateIdle:
 		return tgbotapi.NewReplyKeyboard(
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(kbYesterdayDinner),
-				tgbotapi.NewKeyboardButton(kbTodayMorningStatus),
+				tgbotapi.NewKeyboardButton(l.T(kbYesterdayDinner)),
+				tgbotapi.NewKeyboardButton(l.T(kbTodayMorningStatus)),
 			),
+			tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(l.T(kbMeal))),
 		)
 
 	case models.StateWaitingEvening:
 		return tgbotapi.NewReplyKeyboard(
 			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton(kbDinner),
-				tgbotapi.NewKeyboardButton(kbPrevMorningStatus),
+				tgbotapi.NewKeyboardButton(l.T(kbDinner)),
+				tgbotapi.NewKeyboardButton(l.T(kbPrevMorningStatus)),
 			),
+			tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(l.T(kbMeal))),
 		)
 	default: // notStarted, Initial → скрыть
 		return empty
 	}
 }
 
-func NewHandler(bot *tgbotapi.BotAPI, db *storage.DB) *Handler {
-	return &Handler{Bot: bot, DB: db}
+// handleDayKeyboard запускает flow для кнопок из buildDayKeyboard.
+// Даты считаются в часовом поясе пользователя; false — текст не кнопка.
+func (h *Handler) handleDayKeyboard(chatID int64, text string) bool {
+	key := ""
+	for _, k := range []string{kbYesterdayDinner, kbTodayMorningStatus, kbDinner, kbPrevMorningStatus, kbMeal} {
+		if i18n.Is(text, k) {
+			key = k
+		}
+	}
+	if key == "" {
+		return false
+	}
+	u, _ := h.DB.GetUser(chatID)
+	if u == nil {
+		return false
+	}
+
+	now := h.clock.Now().In(h.userLocation(chatID))
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+
+	switch key {
+	case kbYesterdayDinner:
+		h.askDinner(chatID, yesterday+"-evening")
+	case kbTodayMorningStatus:
+		h.askCheckIn(chatID, today+"-morning")
+	case kbDinner:
+		// после полуночи «ужинал» относится ко вчерашнему вечеру
+		day := today
+		if now.Hour() < 4 {
+			day = yesterday
+		}
+		h.askDinner(chatID, day+"-evening")
+	case kbPrevMorningStatus:
+		h.askCheckIn(chatID, lastMorning(u.MorningAt, now)+"-morning")
+	case kbMeal:
+		h.askMeal(chatID, today)
+	}
+	return true
+}
+
+// lastMorning — день последнего уже наступившего утреннего вопроса
+func lastMorning(morningAt string, now time.Time) string {
+	t, err := time.Parse("15:04", morningAt)
+	if err != nil {
+		return now.Format("2006-01-02")
+	}
+	at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if now.Before(at) {
+		return now.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return now.Format("2006-01-02")
 }
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleHistory(chatID int64) {
+	now := h.clock.Now().In(h.userLocation(chatID))
+	text, kb := h.historyMonth(chatID, now.Format("2006-01"))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = kb
+	h.Bot.Send(msg)
+}
+
+// handleHistoryCallback — навигация по календарю и просмотр дня в том же сообщении
+func (h *Handler) handleHistoryCallback(chatID int64, msgID int, d callback.Data) {
+	var (
+		text string
+		kb   tgbotapi.InlineKeyboardMarkup
+	)
+	switch d.Action {
+	case callback.HistMonth:
+		text, kb = h.historyMonth(chatID, d.Payload)
+	case callback.HistDay:
+		text, kb = h.historyDay(chatID, d.Day())
+	case callback.HistEditM:
+		h.askCheckIn(chatID, d.Day()+"-morning")
+		return
+	case callback.HistEditE:
+		h.askDinner(chatID, d.Day()+"-evening")
+		return
+	default: // HistNop — пустые клетки календаря
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, kb))
+}
+
+// historyMonth рисует календарь месяца month (YYYY-MM): 🔴 — были жалобы,
+// 🟢 — день заполнен без жалоб, · — есть только ужин.
+func (h *Handler) historyMonth(chatID int64, month string) (string, tgbotapi.InlineKeyboardMarkup) {
+	loc := h.userLocation(chatID)
+	l := h.lang(chatID)
+	now := h.clock.Now().In(loc)
+
+	first, err := time.ParseInLocation("2006-01", month, loc)
+	if err != nil || first.After(now) {
+		first = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	last := first.AddDate(0, 1, -1)
+
+	recs, _ := h.DB.ListDayRecords(chatID, first.Format("2006-01-02"), last.Format("2006-01-02"))
+	byDay := map[int]models.DayRecord{}
+	for _, rec := range recs {
+		if t, err := time.Parse("2006-01-02", rec.Day); err == nil {
+			byDay[t.Day()] = rec
+		}
+	}
+
+	nop := func(label string) tgbotapi.InlineKeyboardButton {
+		return callback.Button(label, callback.HistNop, "", "")
+	}
+
+	// ‹ Май 2025 › — листаем от месяца регистрации до текущего
+	var nav []tgbotapi.InlineKeyboardButton
+	if u, _ := h.DB.GetUser(chatID); u != nil && h.since(u, loc).Before(first) {
+		prev := first.AddDate(0, -1, 0)
+		nav = append(nav, callback.Button("‹", callback.HistMonth, "", prev.Format("2006-01")))
+	} else {
+		nav = append(nav, nop(" "))
+	}
+	nav = append(nav, nop(fmt.Sprintf("%s %d", l.List("months")[first.Month()-1], first.Year())))
+	if next := first.AddDate(0, 1, 0); !next.After(now) {
+		nav = append(nav, callback.Button("›", callback.HistMonth, "", next.Format("2006-01")))
+	} else {
+		nav = append(nav, nop(" "))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{nav}
+
+	var week []tgbotapi.InlineKeyboardButton
+	for _, wd := range l.List("weekdays") {
+		week = append(week, nop(wd))
+	}
+	rows = append(rows, week)
+
+	// неделя начинается с понедельника
+	week = nil
+	for i := 0; i < (int(first.Weekday())+6)%7; i++ {
+		week = append(week, nop(" "))
+	}
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		label := fmt.Sprint(d.Day())
+		if rec, ok := byDay[d.Day()]; ok {
+			switch {
+			case stats.Fine(rec):
+				label += "🟢"
+			case rec.MorningAnswered():
+				label += "🔴"
+			default:
+				label += "·"
+			}
+		}
+
+		if d.After(now) {
+			week = append(week, nop(" "))
+		} else {
+			week = append(week, callback.Button(label, callback.HistDay, d.Format("2006-01-02"), ""))
+		}
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, nop(" "))
+		}
+		rows = append(rows, week)
+	}
+
+	return l.T("history.title"), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// markText — что показать вместо ответа: пользователь пропустил вопрос сам
+// или так и не ответил
+func (h *Handler) markText(l i18n.Lang, chatID int64, dateKey string) string {
+	switch mark, _ := h.DB.GetPromptMark(chatID, dateKey); mark {
+	case models.MarkSkipped:
+		return l.T("history.skipped")
+	case models.MarkMissed:
+		return l.T("history.missed")
+	}
+	return "—"
+}
+
+// historyDay — карточка дня с кнопками правки
+func (h *Handler) historyDay(chatID int64, day string) (string, tgbotapi.InlineKeyboardMarkup) {
+	loc := h.userLocation(chatID)
+	l := h.lang(chatID)
+	t, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		return h.historyMonth(chatID, "")
+	}
+
+	rec, _ := h.DB.GetDayRecord(chatID, day)
+	meals, _ := h.DB.ListMeals(chatID, day, day)
+
+	morning, dinner := h.markText(l, chatID, day+"-morning"), h.markText(l, chatID, day+"-evening")
+	if rec != nil && rec.MorningAnswered() {
+		catalog, _ := h.DB.ListSymptoms(chatID)
+		morning = stats.Morning(l, *rec, catalog)
+	}
+	if rec != nil && rec.DinnerAt != nil {
+		dinner = rec.DinnerAt.In(loc).Format("15:04") + hashTags(l, rec.DinnerTags)
+	}
+
+	text := l.T("history.day", t.Format("02.01.2006"), morning, dinner)
+	kb := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("history.edit_m"), callback.HistEditM, day, ""),
+			callback.Button(l.T("history.edit_e"), callback.HistEditE, day, ""),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("history.meals", len(meals)), callback.MealDay, day, ""),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("history.calendar"), callback.HistMonth, "", t.Format("2006-01")),
+		),
+	)
+	return text, kb
+}
+
+// askCheckIn запускает чек-ин за утро dateKey отдельным сообщением
+func (h *Handler) askCheckIn(chatID int64, dateKey string) {
+	h.startFlow(chatID, flowCheckIn, "", dateKey, nil)
+}
+
+// askDinner запускает flow ввода времени ужина для dateKey
+func (h *Handler) askDinner(chatID int64, dateKey string) {
+	h.startFlow(chatID, flowDinner, "", dateKey, nil)
+}
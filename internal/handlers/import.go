@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/export"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	maxImportSize = 1 << 20
+	importListMax = 10 // сколько конфликтов и ошибок показывать в сводке
+)
+
+func (h *Handler) handleImport(chatID int64) {
+	h.sendT(chatID, "import.help")
+}
+
+// handleImportDocument проверяет присланный файл и показывает сводку без записи
+func (h *Handler) handleImportDocument(msg *tgbotapi.Message) {
+	chatID := msg.Chat.ID
+	doc := msg.Document
+	l := h.msgLang(msg)
+
+	if u, _ := h.DB.GetUser(chatID); u == nil {
+		h.send(chatID, l.T("no_user"))
+		return
+	}
+	f, ok := export.ImportFormat(doc.FileName)
+	if !ok {
+		h.send(chatID, l.T("import.only_csv_json"))
+		return
+	}
+	if doc.FileSize > maxImportSize {
+		h.send(chatID, l.T("import.too_big"))
+		return
+	}
+
+	plan, err := h.planImport(chatID, l, f, doc.FileID)
+	if err != nil {
+		h.send(chatID, l.T("import.read_error", err)+"\n"+l.T("import.format_hint"))
+		return
+	}
+
+	reply := tgbotapi.NewMessage(chatID, formatImportPlan(l, plan))
+	reply.ReplyToMessageID = msg.MessageID
+	if len(plan.Add) == 0 && len(plan.Conflicts) == 0 {
+		h.Bot.Send(reply)
+		return
+	}
+
+	row := []tgbotapi.InlineKeyboardButton{
+		callback.Button(l.T("import.btn_apply"), callback.ImportApply, "", doc.FileUniqueID),
+	}
+	if len(plan.Conflicts) > 0 {
+		row = append(row, callback.Button(l.T("import.btn_replace"), callback.ImportReplace, "", doc.FileUniqueID))
+	}
+	row = append(row, callback.Button(l.T("btn.cancel"), callback.ImportCancel, "", doc.FileUniqueID))
+	reply.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	h.Bot.Send(reply)
+
+	// файл ждёт подтверждения в диалоге импорта
+	h.startFlow(chatID, flowImport, "", doc.FileUniqueID, map[string]string{
+		"format": string(f),
+		"file":   doc.FileID,
+	})
+}
+
+// applyImport записывает файл после подтверждения. Файл скачиваем
+// и проверяем заново: дневник мог измениться, пока сводка висела в чате.
+// ImportApply — только новые дни и пустые поля, ImportReplace — ещё и
+// перезаписать конфликты.
+func (h *Handler) applyImport(ev fsm.Event, c *models.Conversation) error {
+	chatID, msgID := ev.ChatID, ev.MsgID
+	l := h.lang(chatID)
+
+	plan, err := h.planImport(chatID, l, export.Format(c.Data["format"]), c.Data["file"])
+	if err != nil {
+		h.send(chatID, l.T("import.read_error", err))
+		return nil
+	}
+	rows := plan.Add
+	replace := c.Data["confirm"] == string(callback.ImportReplace)
+	if replace {
+		for _, c := range plan.Conflicts {
+			rows = append(rows, c.New)
+		}
+	}
+
+	loc := h.userLocation(chatID)
+	recs := make([]models.DayRecord, 0, len(rows))
+	for _, r := range rows {
+		rec := models.DayRecord{ChatID: chatID, Day: r.Day, Complaints: r.Complaints}
+		if r.Score != "" || r.Symptoms != "" {
+			rec.CheckIn = &models.CheckIn{}
+			if v, err := strconv.Atoi(r.Score); err == nil {
+				rec.CheckIn.Score = &v
+			}
+			if r.Symptoms != "" {
+				rec.CheckIn.Symptoms = strings.Split(r.Symptoms, ",")
+			}
+		}
+		if hm, m, ok := strings.Cut(r.Dinner, ":"); ok {
+			hour, _ := strconv.Atoi(hm)
+			min, _ := strconv.Atoi(m)
+			t := h.dinnerTime(r.Day, hour, min, loc)
+			rec.DinnerAt = &t
+		}
+		recs = append(recs, rec)
+	}
+	if err := h.DB.ImportDayRecords(chatID, recs, replace); err != nil {
+		h.send(chatID, l.T("import.failed", err))
+		return nil
+	}
+
+	h.Bot.Send(tgbotapi.NewEditMessageText(chatID, msgID, l.T("import.done", len(recs))))
+	return nil
+}
+
+// importCanceled убирает кнопки со сводки; после /cancel сводку не трогаем
+func (h *Handler) importCanceled(ev fsm.Event, _ *models.Conversation) error {
+	if ev.MsgID != 0 {
+		h.Bot.Send(tgbotapi.NewEditMessageText(ev.ChatID, ev.MsgID, h.lang(ev.ChatID).T("import.canceled")))
+	}
+	return nil
+}
+
+func (h *Handler) planImport(chatID int64, l i18n.Lang, f export.Format, fileID string) (export.Plan, error) {
+	data, err := h.downloadFile(l, fileID)
+	if err != nil {
+		return export.Plan{}, err
+	}
+	rows, err := export.Decode(l, f, data)
+	if err != nil {
+		return export.Plan{}, err
+	}
+
+	loc := h.userLocation(chatID)
+	recs, err := h.DB.ListDayRecords(chatID, "0000-01-01", "9999-12-31")
+	if err != nil {
+		return export.Plan{}, err
+	}
+	existing := make(map[string]export.Row, len(recs))
+	for _, r := range export.Rows(recs, loc) {
+		existing[r.Day] = r
+	}
+	catalog, err := h.DB.ListSymptoms(chatID)
+	if err != nil {
+		return export.Plan{}, err
+	}
+	return export.Check(l, rows, existing, catalog, h.clock.Now().In(loc).Format("2006-01-02")), nil
+}
+
+func (h *Handler) downloadFile(l i18n.Lang, fileID string) ([]byte, error) {
+	url, err := h.Bot.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(h.ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.New(l.T("import.download")) // в ошибке URL с токеном бота
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", l.T("import.download"), resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportSize {
+		return nil, errors.New(l.T("import.too_big"))
+	}
+	return data, nil
+}
+
+func formatImportPlan(l i18n.Lang, p export.Plan) string {
+	var b strings.Builder
+	b.WriteString(l.T("import.plan", p.Total) + "\n\n")
+	b.WriteString(l.T("import.add", len(p.Add)) + "\n")
+	if p.Same > 0 {
+		b.WriteString(l.T("import.same", p.Same) + "\n")
+	}
+
+	if len(p.Conflicts) > 0 {
+		b.WriteString("\n" + l.T("import.conflicts", len(p.Conflicts)) + "\n")
+		for i, c := range p.Conflicts {
+			if i == importListMax {
+				b.WriteString(l.T("import.more", len(p.Conflicts)-i) + "\n")
+				break
+			}
+			fmt.Fprintf(&b, "• %s:%s\n", c.New.Day, conflictDiff(l, c))
+		}
+		b.WriteString(l.T("import.conflicts_hint") + "\n")
+	}
+
+	if len(p.Problems) > 0 {
+		b.WriteString("\n" + l.T("import.problems", len(p.Problems)) + "\n")
+		for i, pr := range p.Problems {
+			if i == importListMax {
+				b.WriteString(l.T("import.more", len(p.Problems)-i) + "\n")
+				break
+			}
+			b.WriteString(l.T("import.problem", pr.N, pr.Reason) + "\n")
+		}
+	}
+
+	if len(p.Add) == 0 && len(p.Conflicts) == 0 {
+		b.WriteString("\n" + l.T("import.nothing"))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func conflictDiff(l i18n.Lang, c export.Conflict) string {
+	var s string
+	if c.Old.Complaints != "" && c.New.Complaints != "" && c.Old.Complaints != c.New.Complaints {
+		s += l.T("import.diff_morning", c.Old.Complaints, c.New.Complaints)
+	}
+	if c.Old.Score != "" && c.New.Score != "" && c.Old.Score != c.New.Score {
+		s += l.T("import.diff_score", c.Old.Score, c.New.Score)
+	}
+	if c.Old.Symptoms != "" && c.New.Symptoms != "" && c.Old.Symptoms != c.New.Symptoms {
+		s += l.T("import.diff_symptoms", c.Old.Symptoms, c.New.Symptoms)
+	}
+	if c.Old.Dinner != "" && c.New.Dinner != "" && c.Old.Dinner != c.New.Dinner {
+		s += l.T("import.diff_dinner", c.Old.Dinner, c.New.Dinner)
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleLanguage(chatID int64) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, l := range i18n.Langs {
+		row = append(row, callback.Button(l.Name(), callback.Lang, "", string(l)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.lang(chatID).T("lang.ask"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	h.Bot.Send(msg)
+}
+
+// handleLanguageSet сохраняет язык и перерисовывает дневную клавиатуру на нём
+func (h *Handler) handleLanguageSet(chatID int64, msgID int, code string) {
+	l, ok := i18n.Parse(code)
+	u, _ := h.DB.GetUser(chatID)
+	if !ok || u == nil {
+		return
+	}
+	u.Lang = string(l)
+	if err := h.DB.UpsertUser(u); err != nil {
+		h.send(chatID, l.T("error", err))
+		return
+	}
+
+	h.Bot.Send(tgbotapi.NewEditMessageText(chatID, msgID, l.T("lang.saved")))
+	h.pushDayKeyboard(chatID)
+}
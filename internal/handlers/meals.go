@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleMeal — /meal и кнопка «Еда»: записать приём пищи за сегодня
+func (h *Handler) handleMeal(chatID int64) {
+	h.askMeal(chatID, h.clock.Now().In(h.userLocation(chatID)).Format("2006-01-02"))
+}
+
+// askMeal запускает запись приёма пищи за день day
+func (h *Handler) askMeal(chatID int64, day string) {
+	h.startFlow(chatID, flowMeal, "", day, nil)
+}
+
+// handleMealsCallback — день дневника питания в том же сообщении;
+// MealDelete удаляет запись payload и перерисовывает день
+func (h *Handler) handleMealsCallback(chatID int64, msgID int, d callback.Data) {
+	if d.Action == callback.MealDelete {
+		id, err := strconv.ParseInt(d.Payload, 10, 64)
+		if err != nil {
+			return
+		}
+		if err := h.DB.DeleteMeal(chatID, id); err != nil {
+			h.sendT(chatID, "error", err)
+			return
+		}
+	}
+	text, kb, err := h.mealsDay(chatID, d.Day())
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, kb))
+}
+
+// mealsDay — все приёмы пищи дня по времени: 🗑 удаляет запись, 📷
+// присылает фото дня, « К дню возвращает в карточку истории
+func (h *Handler) mealsDay(chatID int64, day string) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	l := h.lang(chatID)
+	loc := h.userLocation(chatID)
+	t, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	meals, err := h.DB.ListMeals(chatID, day, day)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	lines := []string{l.T("meal.day", t.Format("02.01.2006")), ""}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	photos := false
+	for _, m := range meals {
+		lines = append(lines, mealLine(l, m, loc))
+		label := fmt.Sprintf("🗑 %s %s", m.At.In(loc).Format("15:04"), l.T("meal."+m.Kind))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callback.Button(label, callback.MealDelete, day, strconv.FormatInt(m.ID, 10))))
+		photos = photos || m.PhotoID != ""
+	}
+	if len(meals) == 0 {
+		lines = append(lines, l.T("meal.empty"))
+	}
+
+	actions := tgbotapi.NewInlineKeyboardRow(callback.Button(l.T("btn.meal_add"), callback.MealAdd, day, ""))
+	if photos {
+		actions = append(actions, callback.Button(l.T("btn.meal_photos"), callback.MealPhotos, day, ""))
+	}
+	rows = append(rows, actions, tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.meal_back"), callback.HistDay, day, "")))
+	return strings.Join(lines, "\n"), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// mealLine: «08:30 Завтрак — овсянка 📷 #сладкое»
+func mealLine(l i18n.Lang, m models.Meal, loc *time.Location) string {
+	s := m.At.In(loc).Format("15:04") + " " + l.T("meal."+m.Kind)
+	if m.Text != "" {
+		s += " — " + m.Text
+	}
+	if m.PhotoID != "" {
+		s += " 📷"
+	}
+	return s + hashTags(l, m.Tags)
+}
+
+// sendMealPhotos присылает фото приёмов пищи дня с подписями
+func (h *Handler) sendMealPhotos(chatID int64, day string) {
+	meals, err := h.DB.ListMeals(chatID, day, day)
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	l := h.lang(chatID)
+	loc := h.userLocation(chatID)
+	for _, m := range meals {
+		if m.PhotoID == "" {
+			continue
+		}
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(m.PhotoID))
+		photo.Caption = mealLine(l, m, loc)
+		h.Bot.Send(photo)
+	}
+}
+
+// ---------- запись (flowMeal) -----------------------------------------------
+
+// closeButtons убирает кнопки сообщения, на которое ответили нажатием
+func (h *Handler) closeButtons(ev fsm.Event) {
+	if ev.Button != "" && ev.MsgID != 0 {
+		h.dropKeyboard(ev.ChatID, ev.MsgID)
+	}
+}
+
+func (h *Handler) askMealKind(ev fsm.Event, c *models.Conversation) error {
+	l := h.lang(ev.ChatID)
+	t, err := time.Parse("2006-01-02", c.Key)
+	if err != nil {
+		return err
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	for _, k := range models.MealKinds {
+		row = append(row, callback.Button(l.T("meal."+k), callback.MealKind, c.Key, k))
+	}
+	msg := tgbotapi.NewMessage(ev.ChatID, l.T("meal.ask_kind", t.Format("02.01.2006")))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	_, err = h.Bot.Send(msg)
+	return err
+}
+
+// askMealTime: кнопка «Сейчас» есть, только если запись за сегодня
+func (h *Handler) askMealTime(ev fsm.Event, c *models.Conversation) error {
+	h.closeButtons(ev)
+	l := h.lang(ev.ChatID)
+	msg := tgbotapi.NewMessage(ev.ChatID, l.T("meal.ask_time", l.T("meal."+c.Data["kind"])))
+	if c.Key == h.clock.Now().In(h.userLocation(ev.ChatID)).Format("2006-01-02") {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("btn.now"), callback.MealNow, c.Key, "")))
+	}
+	_, err := h.Bot.Send(msg)
+	return err
+}
+
+func (h *Handler) takeMealTime(ev fsm.Event, c *models.Conversation) error {
+	t, err := time.Parse("15:04", strings.TrimSpace(ev.Text))
+	if err != nil {
+		return invalid("bad_time")
+	}
+	c.Data["time"] = t.Format("15:04")
+	return nil
+}
+
+func (h *Handler) askMealWhat(ev fsm.Event, c *models.Conversation) error {
+	h.closeButtons(ev)
+	l := h.lang(ev.ChatID)
+	msg := tgbotapi.NewMessage(ev.ChatID, l.T("meal.ask_what"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.skip"), callback.MealSkip, c.Key, "")))
+	_, err := h.Bot.Send(msg)
+	return err
+}
+
+// takeMealWhat принимает описание текстом или фото с подписью
+func (h *Handler) takeMealWhat(ev fsm.Event, c *models.Conversation) error {
+	text := strings.TrimSpace(ev.Text)
+	if text == "" && ev.Photo == "" {
+		return invalid("meal.bad_what")
+	}
+	c.Data["text"], c.Data["photo"] = text, ev.Photo
+	return nil
+}
+
+// pressMeal запоминает нажатое в c.Data: kind и time (для «Сейчас»);
+// теги — см. tagStep
+func (h *Handler) pressMeal(ev fsm.Event, c *models.Conversation) error {
+	switch callback.Action(ev.Button) {
+	case callback.MealKind:
+		if !slices.Contains(models.MealKinds, ev.Payload) {
+			return fsm.ErrStale
+		}
+		c.Data["kind"] = ev.Payload
+	case callback.MealNow:
+		now := h.clock.Now().In(h.userLocation(ev.ChatID))
+		if now.Format("2006-01-02") != c.Key {
+			return invalid("meal.not_today")
+		}
+		c.Data["time"] = now.Format("15:04")
+	}
+	return nil
+}
+
+func (h *Handler) saveMeal(ev fsm.Event, c *models.Conversation) error {
+	loc := h.userLocation(ev.ChatID)
+	at, err := time.ParseInLocation("2006-01-02 15:04", c.Key+" "+c.Data["time"], loc)
+	if err != nil {
+		return err
+	}
+	m := &models.Meal{
+		ChatID:  ev.ChatID,
+		Day:     c.Key,
+		At:      at,
+		Kind:    c.Data["kind"],
+		Text:    c.Data["text"],
+		PhotoID: c.Data["photo"],
+		Tags:    pickedTags(c),
+	}
+	if err := h.DB.AddMeal(m); err != nil {
+		return err
+	}
+	h.closeButtons(ev)
+
+	l := h.lang(ev.ChatID)
+	h.send(ev.ChatID, l.T("meal.saved", mealLine(l, *m, loc)))
+	text, kb, err := h.mealsDay(ev.ChatID, c.Key)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(ev.ChatID, text)
+	msg.ReplyMarkup = kb
+	_, err = h.Bot.Send(msg)
+	return err
+}
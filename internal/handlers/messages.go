@@ -8,96 +8,65 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"telegram-health-dairy/internal/utils"
 	"time"
 
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 var timeRx = regexp.MustCompile(`^\d{1,2}:\d{2}$`)
 
 func (h *Handler) HandleMessage(msg *tgbotapi.Message) {
-	if msg.IsCommand() {
+	switch {
+	case msg.IsCommand():
 		h.HandleCommand(msg)
-	} else {
+	case msg.Document != nil:
+		h.handleImportDocument(msg)
+	default:
 		h.HandleText(msg)
 	}
 }
 
 func (h *Handler) HandleText(msg *tgbotapi.Message) {
 	chatID := msg.Chat.ID
-	state, _ := h.DB.GetUserState(chatID)
-	if state == "" {
+
+	if h.handleMenu(chatID, msg.Text) {
+		return
+	}
+	// кнопки дневной клавиатуры начинают новый flow в любом состоянии
+	if h.handleDayKeyboard(chatID, msg.Text) {
 		return
 	}
-	switch {
-	case state == "setup_morning":
-		if !timeRx.MatchString(msg.Text) {
-			h.send(chatID, "Неверный формат, нужно HH:MM")
-			return
-		}
-		u, _ := h.DB.GetUser(chatID)
-		u.MorningAt = msg.Text
-		_ = h.DB.UpsertUser(u)
-		_ = h.DB.SetUserState(chatID, "setup_evening")
-		h.send(chatID, "Введите время вечернего сообщения HH:MM")
-
-	case state == "setup_evening":
-		if !timeRx.MatchString(msg.Text) {
-			h.send(chatID, "Неверный формат, нужно HH:MM")
-			return
-		}
-		u, _ := h.DB.GetUser(chatID)
-		u.EveningAt = msg.Text
-		_ = h.DB.UpsertUser(u)
-		_ = h.DB.SetUserState(chatID, "setup_timezone")
-		h.send(chatID, "Введите часовой пояс (например Europe/Moscow или +3, -05:30, UTC)")
-
-	case state == "setup_timezone":
-		tz, err := validateTZ(msg.Text)
-
-		if err != nil {
-			utils.LogFor(err)
-			h.send(chatID, "Неверный TZ")
-			return
-		}
-
-		u, _ := h.DB.GetUser(chatID)
-		u.TZ = tz
-
-		_ = h.DB.UpsertUser(u)
-		_ = h.DB.SetUserState(chatID, "")
-
-		h.handleConfirmSettings(chatID)
-
-	case strings.HasPrefix(state, "wait_complaints:"):
-		dateKey := strings.TrimPrefix(state, "wait_complaints:")
-
-		// 1) шлём сообщение-подтверждение реплаем на текст пользователя
-		confirm := tgbotapi.NewMessage(chatID, "Сохраняем текущий статус?")
-		confirm.ReplyToMessageID = msg.MessageID
-		confirm.ReplyMarkup = confirmKB
-		_, _ = h.Bot.Send(confirm)
 
-		// 2) переключаемся в состояние "confirm_complaints:DATE"
-		_ = h.DB.SetUserState(chatID, "confirm_complaints:"+dateKey)
+	h.handleFlowText(msg)
+}
 
-	case strings.HasPrefix(state, "wait_dinner:"):
-		if !timeRx.MatchString(msg.Text) {
-			h.send(chatID, "Неверный формат, нужно HH:MM")
-			return
-		}
-		parts := strings.Split(msg.Text, ":")
-		hStr, _ := strconv.Atoi(parts[0])
-		mStr, _ := strconv.Atoi(parts[1])
-		now := time.Now()
-		dinner := time.Date(now.Year(), now.Month(), now.Day(), hStr, mStr, 0, 0, time.UTC)
-		dateKey := strings.TrimPrefix(state, "wait_dinner:")
-		h.DB.SetDinner(chatID, dateKey[:10], dinner)
-		h.DB.DeletePending(chatID, dateKey)
-		_ = h.DB.SetUserState(chatID, "")
-		h.send(chatID, "Время ужина сохранено!")
+// handleMenu — кнопки главного меню; до подтверждения настроек они, как и
+// команды, недоступны. false — текст не из меню.
+func (h *Handler) handleMenu(chatID int64, text string) bool {
+	st, _ := h.DB.GetSessionState(chatID)
+	if !validateInitialState(st, "") {
+		return false
 	}
+	switch {
+	case i18n.Is(text, "menu.stats"):
+		h.handleStats(chatID)
+	case i18n.Is(text, "menu.morning"):
+		h.startFlow(chatID, flowSetup, "morning", "", nil)
+	case i18n.Is(text, "menu.evening"):
+		h.startFlow(chatID, flowSetup, "evening", "", nil)
+	case i18n.Is(text, "menu.tz"):
+		h.startFlow(chatID, flowSetup, "tz", "", nil)
+	case i18n.Is(text, "menu.clear"):
+		_ = h.DB.ClearData(chatID)
+		h.sched.Remove(chatID)
+		h.sendT(chatID, "data.cleared")
+	default:
+		return false
+	}
+	return true
 }
 
 var offRx = regexp.MustCompile(`^(?i)(?:gmt|utc)?([+-]\d{1,2})(?::?(\d{2}))?$`)
@@ -159,3 +128,41 @@ func tzToLocation(tz string) (*time.Location, error) {
 	sec := h*3600 + int(math.Copysign(float64(min*60), float64(h)))
 	return time.FixedZone(tz, sec), nil
 }
+
+// userLocation — часовой пояс пользователя, UTC если он не задан или битый
+func (h *Handler) userLocation(chatID int64) *time.Location {
+	u, _ := h.DB.GetUser(chatID)
+	if u == nil {
+		return time.UTC
+	}
+	loc, err := tzToLocation(u.TZ)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// since — начало дневника: регистрация или первый импортированный день,
+// если он раньше
+func (h *Handler) since(u *models.User, loc *time.Location) time.Time {
+	t := time.Unix(u.CreatedAt, 0).In(loc)
+	if day, _ := h.DB.FirstDay(u.ChatID); day != "" {
+		if first, err := time.ParseInLocation("2006-01-02", day, loc); err == nil && first.Before(t) {
+			return first
+		}
+	}
+	return t
+}
+
+// dinnerTime — ужин hh:mm вечера дня day в loc; время до 04:00 — это уже
+// следующие сутки (ужин после полуночи), а не утро того же дня.
+func (h *Handler) dinnerTime(day string, hour, min int, loc *time.Location) time.Time {
+	d, err := time.ParseInLocation("2006-01-02", day, loc)
+	if err != nil {
+		d = h.clock.Now().In(loc)
+	}
+	if hour < 4 {
+		d = d.AddDate(0, 0, 1)
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), hour, min, 0, 0, loc)
+}
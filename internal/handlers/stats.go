@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/report"
+	"telegram-health-dairy/internal/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleStats(chatID int64) {
+	text, kb, err := h.buildStats(chatID, stats.Week)
+	if err != nil {
+		h.sendT(chatID, "stats.error", err)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = kb
+	h.Bot.Send(msg)
+}
+
+// handleStatsPeriod перерисовывает отчёт в том же сообщении
+func (h *Handler) handleStatsPeriod(chatID int64, msgID int, period string) {
+	days, err := strconv.Atoi(period)
+	if err != nil || days <= 0 {
+		return
+	}
+	text, kb, err := h.buildStats(chatID, stats.Period(days))
+	if err != nil {
+		h.sendT(chatID, "stats.error", err)
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, kb))
+}
+
+func (h *Handler) buildStats(chatID int64, p stats.Period) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	u, err := h.DB.GetUser(chatID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+	if u == nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, errors.New(i18n.Default.T("no_user"))
+	}
+	l := i18n.Of(u.Lang)
+	loc, err := tzToLocation(u.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now := h.clock.Now().In(loc)
+	from, to := stats.Range(p, now)
+	recs, err := h.DB.ListDayRecords(chatID, from, to)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	report := stats.Build(recs, p, h.since(u, loc), now)
+	return stats.Format(l, report), statsKeyboard(l, p), nil
+}
+
+func statsKeyboard(l i18n.Lang, active stats.Period) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, p := range stats.Periods {
+		label := l.T("days.short", int(p))
+		if p == active {
+			label = "• " + label
+		}
+		row = append(row, callback.Button(label, callback.Stats, "", strconv.Itoa(int(p))))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row, chartsRow(l), tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.triggers"), callback.Triggers, "", ""),
+	))
+}
+
+// correlationDays — за сколько дней смотрим связь ужина и утра
+const correlationDays = 365
+
+func (h *Handler) handleCorrelation(chatID int64) {
+	u, err := h.DB.GetUser(chatID)
+	if err != nil || u == nil {
+		h.sendT(chatID, "no_user")
+		return
+	}
+	l := i18n.Of(u.Lang)
+	loc, err := tzToLocation(u.TZ)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now := h.clock.Now().In(loc)
+	from, to := stats.Range(correlationDays, now)
+	recs, err := h.DB.ListDayRecords(chatID, from, to)
+	if err != nil {
+		h.send(chatID, l.T("corr.error", err))
+		return
+	}
+
+	h.send(chatID, stats.FormatCorrelation(l, stats.Correlate(recs, loc)))
+}
+
+// handleTriggers — какие теги еды чаще других предшествуют жалобам утром;
+// период тот же, что у связи ужина и утра
+func (h *Handler) handleTriggers(chatID int64) {
+	l := h.lang(chatID)
+	loc := h.userLocation(chatID)
+	from, to := stats.Range(correlationDays, h.clock.Now().In(loc))
+	recs, err := h.DB.ListDayRecords(chatID, from, to)
+	if err != nil {
+		h.send(chatID, l.T("corr.error", err))
+		return
+	}
+	meals, err := h.DB.ListMeals(chatID, from, to)
+	if err != nil {
+		h.send(chatID, l.T("corr.error", err))
+		return
+	}
+	h.send(chatID, stats.FormatTriggers(l, stats.BuildTriggers(recs, meals, loc)))
+}
+
+// reportDays — период PDF-отчёта по умолчанию; /report 90 — за 90 дней
+const (
+	reportDays    = 30
+	maxReportDays = 365
+)
+
+func (h *Handler) handleReport(chatID int64, args string) {
+	days := reportDays
+	if args = strings.TrimSpace(args); args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n <= 0 || n > maxReportDays {
+			h.sendT(chatID, "report.days", maxReportDays)
+			return
+		}
+		days = n
+	}
+
+	u, err := h.DB.GetUser(chatID)
+	if err != nil || u == nil {
+		h.sendT(chatID, "no_user")
+		return
+	}
+	l := i18n.Of(u.Lang)
+	loc := h.userLocation(chatID)
+	now := h.clock.Now().In(loc)
+
+	p := stats.Period(days)
+	from, to := stats.Range(p, now)
+	recs, err := h.DB.ListDayRecords(chatID, from, to)
+	if err != nil {
+		h.send(chatID, l.T("report.error", err))
+		return
+	}
+	// связь ужина с утром считаем по всей истории — в коротком периоде мало пар
+	cfrom, _ := stats.Range(correlationDays, now)
+	all, err := h.DB.ListDayRecords(chatID, cfrom, to)
+	if err != nil {
+		h.send(chatID, l.T("report.error", err))
+		return
+	}
+
+	catalog, _ := h.DB.ListSymptoms(chatID)
+	since := h.since(u, loc)
+
+	pdf, err := report.PDF(report.Data{
+		Lang:        l,
+		User:        u,
+		TZ:          gmtString(u.TZ, now),
+		Loc:         loc,
+		Records:     recs,
+		Symptoms:    catalog,
+		Since:       since,
+		Summary:     stats.Build(recs, p, since, now),
+		Correlation: stats.Correlate(all, loc),
+		Generated:   now,
+	})
+	if err != nil {
+		h.send(chatID, l.T("report.error", err))
+		return
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("health-report_%s_%s.pdf", from, to),
+		Bytes: pdf,
+	})
+	doc.Caption = l.T("report.caption", days)
+	h.Bot.Send(doc)
+}
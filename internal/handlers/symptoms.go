@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxSymptomName — длиннее название не влезет в кнопку
+const maxSymptomName = 32
+
+// handleSymptoms — /symptoms: свой список симптомов для утреннего чек-ина
+func (h *Handler) handleSymptoms(chatID int64) {
+	text, kb, err := h.symptomsMenu(chatID)
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = kb
+	h.Bot.Send(msg)
+}
+
+// handleSymptomsCallback — правка списка в том же сообщении; payload — ключ
+// симптома, у SymFreq — период в днях
+func (h *Handler) handleSymptomsCallback(chatID int64, msgID int, d callback.Data) {
+	list, err := h.DB.ListSymptoms(chatID)
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	var active []string
+	for _, s := range list {
+		if !s.Archived {
+			active = append(active, s.Key)
+		}
+	}
+	known := slices.ContainsFunc(list, func(s models.Symptom) bool { return s.Key == d.Payload })
+	if !known && d.Action != callback.SymAdd && d.Action != callback.SymFreq {
+		h.refreshSymptoms(chatID, msgID)
+		return
+	}
+
+	switch d.Action {
+	case callback.SymAdd:
+		if len(active) >= models.MaxSymptoms {
+			h.sendT(chatID, "symptoms.full")
+			return
+		}
+		h.startFlow(chatID, flowSymptoms, "add", "", map[string]string{"menu": strconv.Itoa(msgID)})
+		return
+	case callback.SymRename:
+		h.startFlow(chatID, flowSymptoms, "rename", d.Payload, map[string]string{"menu": strconv.Itoa(msgID)})
+		return
+	case callback.SymFreq:
+		h.handleSymptomFreq(chatID, msgID, d.Payload)
+		return
+	case callback.SymUp:
+		if i := slices.Index(active, d.Payload); i > 0 {
+			active[i-1], active[i] = active[i], active[i-1]
+			err = h.DB.ReorderSymptoms(chatID, active)
+		}
+	case callback.SymArchive:
+		err = h.DB.ArchiveSymptom(chatID, d.Payload, true)
+	case callback.SymRestore:
+		if len(active) >= models.MaxSymptoms {
+			h.sendT(chatID, "symptoms.full")
+			return
+		}
+		err = h.DB.ArchiveSymptom(chatID, d.Payload, false)
+	}
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	h.refreshSymptoms(chatID, msgID)
+}
+
+// refreshSymptoms перерисовывает меню списка в сообщении msgID
+func (h *Handler) refreshSymptoms(chatID int64, msgID int) {
+	text, kb, err := h.symptomsMenu(chatID)
+	if err != nil {
+		h.sendT(chatID, "error", err)
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, kb))
+}
+
+// symptomsMenu: у активного симптома ✏️ — переименовать, ⬆️ — выше,
+// 🗄 — в архив; архивный ♻️ возвращается в список
+func (h *Handler) symptomsMenu(chatID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	l := h.lang(chatID)
+	list, err := h.DB.ListSymptoms(chatID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	first := true
+	for _, s := range list {
+		name := stats.SymptomName(l, s)
+		if s.Archived {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				callback.Button("♻️ "+name, callback.SymRestore, "", s.Key)))
+			continue
+		}
+		row := tgbotapi.NewInlineKeyboardRow(callback.Button("✏️ "+name, callback.SymRename, "", s.Key))
+		if !first {
+			row = append(row, callback.Button("⬆️", callback.SymUp, "", s.Key))
+		}
+		row = append(row, callback.Button("🗄", callback.SymArchive, "", s.Key))
+		rows = append(rows, row)
+		first = false
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.sym_add"), callback.SymAdd, "", ""),
+		callback.Button(l.T("btn.sym_freq"), callback.SymFreq, "", ""),
+	))
+	return l.T("symptoms.title"), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// handleSymptomFreq — частота симптомов. Из меню ("" вместо периода) —
+// новым сообщением за месяц, кнопки периода перерисовывают его.
+func (h *Handler) handleSymptomFreq(chatID int64, msgID int, period string) {
+	p := stats.Month
+	if period != "" {
+		days, err := strconv.Atoi(period)
+		if err != nil || days <= 0 {
+			return
+		}
+		p = stats.Period(days)
+	}
+
+	l := h.lang(chatID)
+	from, to := stats.Range(p, h.clock.Now().In(h.userLocation(chatID)))
+	recs, err := h.DB.ListDayRecords(chatID, from, to)
+	if err != nil {
+		h.sendT(chatID, "stats.error", err)
+		return
+	}
+	catalog, err := h.DB.ListSymptoms(chatID)
+	if err != nil {
+		h.sendT(chatID, "stats.error", err)
+		return
+	}
+	text := stats.FormatFrequency(l, stats.BuildFrequency(recs, catalog, p, from, to))
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, pp := range stats.Periods {
+		label := l.T("days.short", int(pp))
+		if pp == p {
+			label = "• " + label
+		}
+		row = append(row, callback.Button(label, callback.SymFreq, "", strconv.Itoa(int(pp))))
+	}
+	kb := tgbotapi.NewInlineKeyboardMarkup(row)
+
+	if period == "" {
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = kb
+		h.Bot.Send(msg)
+		return
+	}
+	h.Bot.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, kb))
+}
+
+// ---------- add / rename (flowSymptoms) -------------------------------------
+
+func (h *Handler) askRename(ev fsm.Event, c *models.Conversation) error {
+	l := h.lang(ev.ChatID)
+	list, err := h.DB.ListSymptoms(ev.ChatID)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(list, func(s models.Symptom) bool { return s.Key == c.Key })
+	if i < 0 {
+		return invalid("symptoms.gone")
+	}
+	h.send(ev.ChatID, l.T("symptoms.ask_rename", stats.SymptomName(l, list[i])))
+	return nil
+}
+
+// takeSymptomName: название непустое, не длиннее maxSymptomName и не
+// совпадает с другим симптомом списка
+func (h *Handler) takeSymptomName(ev fsm.Event, c *models.Conversation) error {
+	name := strings.Join(strings.Fields(ev.Text), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxSymptomName {
+		return invalid("symptoms.bad_name")
+	}
+	l := h.lang(ev.ChatID)
+	list, err := h.DB.ListSymptoms(ev.ChatID)
+	if err != nil {
+		return err
+	}
+	active := 0
+	for _, s := range list {
+		if s.Key != c.Key && strings.EqualFold(stats.SymptomName(l, s), name) {
+			return invalid("symptoms.exists")
+		}
+		if !s.Archived {
+			active++
+		}
+	}
+	if c.Key == "" && active >= models.MaxSymptoms {
+		return invalid("symptoms.full")
+	}
+	c.Data["name"] = name
+	return nil
+}
+
+func (h *Handler) saveSymptom(ev fsm.Event, c *models.Conversation) error {
+	var err error
+	if c.Key == "" {
+		_, err = h.DB.AddSymptom(ev.ChatID, c.Data["name"])
+	} else {
+		err = h.DB.RenameSymptom(ev.ChatID, c.Key, c.Data["name"])
+	}
+	if err != nil {
+		return err
+	}
+	if id, _ := strconv.Atoi(c.Data["menu"]); id != 0 {
+		h.refreshSymptoms(ev.ChatID, id)
+	}
+	h.sendT(ev.ChatID, "symptoms.saved", c.Data["name"])
+	return nil
+}
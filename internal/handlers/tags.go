@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/fsm"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Свой тег уходит в callback data кнопки целиком, поэтому ограничен и в
+// символах, и в байтах. Кнопками предлагаются только maxCustomTags своих
+// тегов, которыми отмечали еду последними; остальные можно ввести текстом.
+const (
+	maxTagName    = 20
+	maxTagBytes   = 40
+	maxCustomTags = 9
+)
+
+// askDinnerTags — после ответа на вечерний вопрос: что было на ужин.
+// Уже отмеченные теги (правка из истории) показываются выбранными.
+func (h *Handler) askDinnerTags(chatID int64, dateKey string) {
+	data := map[string]string{}
+	if rec, _ := h.DB.GetDayRecord(chatID, dateKey[:10]); rec != nil {
+		data["tags"] = strings.Join(rec.DinnerTags, ",")
+	}
+	h.startFlow(chatID, flowTags, "", dateKey, data)
+}
+
+func (h *Handler) saveDinnerTags(ev fsm.Event, c *models.Conversation) error {
+	tags := pickedTags(c)
+	if err := h.DB.SetDinnerTags(ev.ChatID, c.Key[:10], tags); err != nil {
+		return err
+	}
+	h.closeButtons(ev)
+	l := h.lang(ev.ChatID)
+	if len(tags) == 0 {
+		h.send(ev.ChatID, l.T("dinner.tags_none"))
+		return nil
+	}
+	h.send(ev.ChatID, l.T("dinner.tags_saved", strings.TrimSpace(hashTags(l, tags))))
+	return nil
+}
+
+// hashTags: « #острое #шаурма»
+func hashTags(l i18n.Lang, tags []string) string {
+	s := ""
+	for _, tag := range tags {
+		s += " #" + stats.TagName(l, tag)
+	}
+	return s
+}
+
+// ---------- шаг выбора тегов ------------------------------------------------
+
+// tagStep — шаг name: теги еды переключаются кнопками tag, done завершает
+// сценарий, а текстом можно добавить свой тег. Выбранное лежит в
+// c.Data["tags"] через запятую.
+func (h *Handler) tagStep(name string, tag, done callback.Action, ask string) fsm.Step {
+	return fsm.Step{
+		Enter: h.showTags(tag, done, ask),
+		Input: h.takeCustomTag,
+		Next:  name,
+		Buttons: map[string]string{
+			string(tag):  name,
+			string(done): fsm.End,
+		},
+		Press: func(ev fsm.Event, c *models.Conversation) error {
+			if callback.Action(ev.Button) != tag {
+				return nil
+			}
+			list := h.tagChoices(ev.ChatID, c)
+			if !slices.Contains(list, ev.Payload) {
+				return fsm.ErrStale
+			}
+			picked := pickedTags(c)
+			var res []string
+			for _, t := range list {
+				if slices.Contains(picked, t) != (t == ev.Payload) {
+					res = append(res, t)
+				}
+			}
+			c.Data["tags"] = strings.Join(res, ",")
+			return nil
+		},
+	}
+}
+
+// showTags перерисовывает теги под нажатым сообщением, а при входе на шаг
+// присылает их новым сообщением с вопросом ask
+func (h *Handler) showTags(tag, done callback.Action, ask string) fsm.Hook {
+	return func(ev fsm.Event, c *models.Conversation) error {
+		l := h.lang(ev.ChatID)
+		picked := pickedTags(c)
+
+		var rows [][]tgbotapi.InlineKeyboardButton
+		var row []tgbotapi.InlineKeyboardButton
+		for _, t := range h.tagChoices(ev.ChatID, c) {
+			label := stats.TagName(l, t)
+			if slices.Contains(picked, t) {
+				label = "✅ " + label
+			}
+			row = append(row, callback.Button(label, tag, c.Key, t))
+			if len(row) == 3 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("btn.checkin_done"), done, c.Key, "")))
+		kb := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+		if callback.Action(ev.Button) == tag && ev.MsgID != 0 {
+			_, err := h.Bot.Request(tgbotapi.NewEditMessageReplyMarkup(ev.ChatID, ev.MsgID, kb))
+			return err
+		}
+		h.closeButtons(ev)
+		msg := tgbotapi.NewMessage(ev.ChatID, l.T(ask))
+		msg.ReplyMarkup = kb
+		_, err := h.Bot.Send(msg)
+		return err
+	}
+}
+
+// takeCustomTag добавляет свой тег к выбранным. Название встроенного или
+// уже знакомого тега выбирает его, а не заводит новый.
+func (h *Handler) takeCustomTag(ev fsm.Event, c *models.Conversation) error {
+	name := strings.Join(strings.Fields(ev.Text), " ")
+	if name == "" || strings.Contains(name, ",") ||
+		utf8.RuneCountInString(name) > maxTagName || len(name) > maxTagBytes {
+		return invalid("tags.bad_name")
+	}
+	l := h.lang(ev.ChatID)
+	tag := name
+	used, _ := h.DB.ListFoodTags(ev.ChatID)
+	for _, t := range slices.Concat(models.MealTags, used, pickedTags(c)) {
+		if strings.EqualFold(t, name) || strings.EqualFold(stats.TagName(l, t), name) {
+			tag = t
+		}
+	}
+	if picked := pickedTags(c); !slices.Contains(picked, tag) {
+		c.Data["tags"] = strings.Join(append(picked, tag), ",")
+	}
+	return nil
+}
+
+// tagChoices — встроенные теги, потом maxCustomTags недавних своих и
+// выбранные в этом диалоге (в том числе введённые текстом)
+func (h *Handler) tagChoices(chatID int64, c *models.Conversation) []string {
+	list := slices.Clone(models.MealTags)
+	used, _ := h.DB.ListFoodTags(chatID)
+	custom := 0
+	for _, t := range used {
+		if custom < maxCustomTags && !slices.Contains(list, t) {
+			list = append(list, t)
+			custom++
+		}
+	}
+	for _, t := range pickedTags(c) {
+		if !slices.Contains(list, t) {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+func pickedTags(c *models.Conversation) []string {
+	if c.Data["tags"] == "" {
+		return nil
+	}
+	return strings.Split(c.Data["tags"], ",")
+}
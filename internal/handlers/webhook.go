@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"telegram-health-dairy/internal/config"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	secretHeader   = "X-Telegram-Bot-Api-Secret-Token"
+	maxUpdateBytes = 1 << 20 // апдейт с запасом помещается в мегабайт
+)
+
+// WebhookHandler принимает апдейты от Telegram и обрабатывает их синхронно:
+// ответ 200 уходит только после обработки, поэтому инстанс можно гасить
+// между запросами. Запросы без верного секрета отклоняются.
+func (h *Handler) WebhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.Header.Get(secretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var upd tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateBytes)).Decode(&upd); err != nil {
+			http.Error(w, "bad update: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !h.dispatch(upd) {
+			// бот останавливается — Telegram повторит доставку позже
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// newWebhookServer регистрирует вебхук (если задан публичный URL)
+// и собирает HTTP-сервер; запускает его serveWebhook.
+func (h *Handler) newWebhookServer(cfg config.WebhookConfig) (*http.Server, error) {
+	if cfg.URL != "" {
+		if err := h.setWebhook(cfg.URL, cfg.Secret); err != nil {
+			return nil, err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, h.WebhookHandler(cfg.Secret))
+
+	return &http.Server{
+		Addr:              cfg.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+// serveWebhook блокируется до Shutdown (тогда возвращает http.ErrServerClosed)
+func (h *Handler) serveWebhook(cfg config.WebhookConfig) error {
+	log.Printf("🌐 Вебхук слушает %s%s", cfg.Listen, cfg.Path)
+	if cfg.TLSCert != "" {
+		return h.srv.ListenAndServeTLS(cfg.TLSCert, cfg.TLSKey)
+	}
+	return h.srv.ListenAndServe()
+}
+
+// setWebhook: WebhookConfig из tgbotapi не умеет secret_token, шлём запрос сами
+func (h *Handler) setWebhook(url, secret string) error {
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", url)
+	params.AddNonEmpty("secret_token", secret)
+
+	_, err := h.Bot.MakeRequest("setWebhook", params)
+	return err
+}
@@ -0,0 +1,126 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/clock"
+	"telegram-health-dairy/internal/config"
+	"telegram-health-dairy/internal/handlers"
+	"telegram-health-dairy/internal/storage"
+)
+
+const secret = "s3cret"
+
+// newWebhook — бот в режиме вебхука; Shutdown вызывает сам тест или Cleanup
+func newWebhook(t *testing.T) (*handlers.Handler, *bot.Fake, func()) {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2025, 5, 8, 9, 0, 0, 0, time.UTC))
+	db, err := storage.Open(storage.DriverSQLite, filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatalf("storage.Open: %v", err)
+	}
+	db.SetClock(clk)
+
+	f := bot.NewFake()
+	f.Now = clk.Now
+	h, err := handlers.Register(context.Background(), f, db, clk, config.Config{
+		UpdateMode: config.ModeWebhook,
+		Webhook:    config.WebhookConfig{Listen: "127.0.0.1:0", Path: "/tg", Secret: secret},
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	stopped := false
+	shutdown := func() {
+		if !stopped {
+			stopped = true
+			if err := h.Shutdown(5 * time.Second); err != nil {
+				t.Errorf("Shutdown: %v", err)
+			}
+		}
+	}
+	t.Cleanup(func() {
+		shutdown()
+		f.Close()
+		db.Close()
+	})
+	return h, f, shutdown
+}
+
+func post(h http.Handler, method, secretToken, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/tg", strings.NewReader(body))
+	if secretToken != "" {
+		r.Header.Set("X-Telegram-Bot-Api-Secret-Token", secretToken)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestWebhookRejects(t *testing.T) {
+	h, f, _ := newWebhook(t)
+	wh := h.WebhookHandler(secret)
+	start, _ := json.Marshal(f.Text(chatID, "/start"))
+
+	tests := []struct {
+		name   string
+		method string
+		secret string
+		body   string
+		want   int
+	}{
+		{"метод", http.MethodGet, secret, string(start), http.StatusMethodNotAllowed},
+		{"без секрета", http.MethodPost, "", string(start), http.StatusForbidden},
+		{"чужой секрет", http.MethodPost, "other", string(start), http.StatusForbidden},
+		{"не JSON", http.MethodPost, secret, "{", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if w := post(wh, tc.method, tc.secret, tc.body); w.Code != tc.want {
+				t.Errorf("код = %d; want %d", w.Code, tc.want)
+			}
+		})
+	}
+	if w := post(wh, http.MethodGet, secret, ""); w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Allow = %q; want POST", w.Header().Get("Allow"))
+	}
+	if sent := f.Sent(); len(sent) != 0 {
+		t.Errorf("отклонённые апдейты обработаны: %+v", sent)
+	}
+
+	// пустой секрет в настройках не пропускает запросы без заголовка
+	if w := post(h.WebhookHandler(""), http.MethodPost, "", string(start)); w.Code != http.StatusForbidden {
+		t.Errorf("без секрета в настройках: код = %d; want 403", w.Code)
+	}
+}
+
+func TestWebhookDelivers(t *testing.T) {
+	h, f, shutdown := newWebhook(t)
+	wh := h.WebhookHandler(secret)
+	start, _ := json.Marshal(f.Text(chatID, "/start"))
+
+	// апдейт обработан до ответа: /start уже прислал настройки
+	if w := post(wh, http.MethodPost, secret, string(start)); w.Code != http.StatusOK {
+		t.Fatalf("код = %d; want 200", w.Code)
+	}
+	if len(f.Sent()) == 0 {
+		t.Fatal("после 200 бот ничего не отправил")
+	}
+
+	shutdown()
+	n := len(f.Sent())
+	if w := post(wh, http.MethodPost, secret, string(start)); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("после Shutdown код = %d; want 503", w.Code)
+	}
+	if len(f.Sent()) != n {
+		t.Error("апдейт обработан после Shutdown")
+	}
+}
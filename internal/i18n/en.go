@@ -0,0 +1,308 @@
+package i18n
+
+var en = map[string]string{
+	"lang.name":      "English",
+	"lang.ask":       "Choose your language",
+	"lang.saved":     "Interface language: English",
+	"weekdays":       "Mo|Tu|We|Th|Fr|Sa|Su",
+	"months":         "January|February|March|April|May|June|July|August|September|October|November|December",
+	"days.one":       "%d day",
+	"days.other":     "%d days",
+	"days.short":     "%d d",
+	"error":          "Error: %s",
+	"no_user":        "User not found, send /start",
+	"bad_hm":         "Wrong format, expected HH:MM",
+	"bad_time":       "Wrong time, expected HH:MM",
+	"bad_tz":         "Unknown time zone",
+	"bad_every":      "Expected a number of minutes from 5 to 120",
+	"bad_max":        "Expected a number from 0 to 10",
+	"bad_quiet":      "Expected HH:MM-HH:MM or \"-\"",
+	"btn.yes":        "Yes",
+	"btn.cancel":     "Cancel",
+	"btn.change":     "Change",
+	"btn.confirm":    "Confirm",
+	"btn.skip":       "Skip",
+	"btn.skip_all":   "Skip all",
+	"callback.stale": "This button is outdated, repeat the command",
+	"cancel.done":    "Canceled",
+	"cancel.none":    "Nothing to cancel",
+	"flow.expired":   "This question has expired, please start over",
+
+	// commands and menu
+	"help": "/start — start\n/stats — statistics\n/correlation — dinner and next-morning wellbeing\n/charts — charts\n" +
+		"/history — history and editing past days\n/symptoms — your own symptom list\n/meal — log a meal\n/export — download the diary (CSV, JSON, XLSX)\n" +
+		"/import — upload history from CSV or JSON\n/report — PDF report for your doctor\n/language — language\n/cancel — cancel the current question\n/help — help",
+	"reset.done":        "Database deleted, restart the bot",
+	"initial.confirm":   "Please confirm your settings before using the bot",
+	"menu.stats":        "Show statistics",
+	"menu.morning":      "Set morning message time",
+	"menu.evening":      "Set evening message time",
+	"menu.tz":           "Change time zone",
+	"menu.clear":        "Clear data",
+	"data.cleared":      "Data cleared",
+	"start.running":     "The bot is already running.\nCurrent state: %s\n\nTo change settings, send /settings",
+	"state.current":     "Current state: %s",
+	"settings.current":  "Current settings:\nMorning: %s\nEvening: %s\nTime zone: %s",
+	"settings.saved":    "Settings saved!",
+	"ask.morning_at":    "Enter the morning message time HH:MM",
+	"ask.evening_at":    "Enter the evening message time HH:MM",
+	"ask.tz_full":       "Enter your time zone (e.g. Europe/London or +3, -05:30, UTC)",
+	"btn.reminders":     "🔔 Reminders",
+	"settings.remind":   "Reminders: %s\nQuiet hours: %s",
+	"remind.policy":     "every %d min, at most %s",
+	"remind.off":        "off",
+	"times.one":         "%d time",
+	"times.other":       "%d times",
+	"quiet.off":         "none",
+	"ask.remind_every":  "How many minutes between reminders about an unanswered question? A number from 5 to 120 (now %d)",
+	"ask.remind_max":    "How many reminders at most? From 0 to 10, 0 — no reminders (now %d)",
+	"ask.quiet":         "Quiet hours with no reminders: a range like 23:00-08:00, or \"-\" to turn them off (now %s)",
+	"remind.saved":      "Done!\n%s",
+	"debug.periods":     "Current state: %s\nUTC: %s\nLocal time: %s (%s)\n\n\"Morning\" window: %s — %s\n\"Evening\" window: %s — %s\n\nNext event: %s (in %v)",
+	"debug.morning":     "morning window",
+	"debug.morning_end": "end of morning window",
+	"debug.evening":     "evening window",
+	"debug.evening_end": "end of evening window",
+	"debug.tomorrow":    "tomorrow morning",
+
+	// day keyboard
+	"kb.yesterday_dinner": "Yesterday's dinner at …",
+	"kb.today_morning":    "This morning's wellbeing",
+	"kb.dinner":           "Had dinner at …",
+	"kb.prev_morning":     "Last morning's wellbeing",
+	"kb.meal":             "🍽 Meals",
+
+	// prompts and answers
+	"prompt.morning":       "Good morning! How are you feeling? Rate it from 0 to 10 and tick any symptoms",
+	"prompt.evening.one":   "Dinner time! %d hour left until the end of the day.",
+	"prompt.evening.other": "Dinner time! %d hours left until the end of the day.",
+	"prompt.remind":        "Don't forget to answer 🙂|Reminder: still waiting for your answer above 🙏|Last reminder: the question will close soon",
+	"prompt.expired":       "⌛ The %s question closed without an answer. You can still fill it in later",
+	"prompt.label.morning": "%s morning",
+	"prompt.label.evening": "%s dinner",
+	"btn.ate_now":          "Ate now",
+	"btn.ate_at":           "Ate at …",
+	"btn.snooze_min":       "⏰ %d min",
+	"btn.snooze_h":         "⏰ %d h",
+	"btn.skip_today":       "Skip today",
+	"prompt.snooze_ok":     "OK, I'll remind you at %s",
+	"prompt.snoozed":       "⏰ Reminding you as asked: %s",
+	"prompt.skipped":       "OK, skipping %s",
+	"ask.dinner":           "When was dinner (%s)? Enter time HH:MM",
+	"dinner.confirm":       "Save dinner at %s (%s)?",
+	"dinner.saved":         "Dinner time saved!",
+	"dinner.enjoy":         "Have a nice evening!",
+
+	// morning check-in
+	"btn.no_complaints": "✅ No complaints",
+	"btn.checkin_note":  "✍️ Add text",
+	"btn.checkin_done":  "💾 Done",
+	"checkin.ask":       "Wellbeing (%s): a score from 0 to 10 and symptoms, then “Done”. You can also just type it",
+	"checkin.note_ask":  "Describe how you feel",
+	"checkin.empty":     "Pick a score, symptoms or “No complaints”",
+	"checkin.saved":     "Thanks, saved: %s",
+	"checkin.none":      "no complaints",
+	"checkin.score":     "wellbeing %d/%d",
+
+	"symptom.heartburn":    "Heartburn",
+	"symptom.bloating":     "Bloating",
+	"symptom.nausea":       "Nausea",
+	"symptom.belching":     "Belching",
+	"symptom.stomach_pain": "Stomach pain",
+	"symptom.headache":     "Headache",
+	"symptom.poor_sleep":   "Poor sleep",
+
+	// own symptom list
+	"symptoms.title": "🩺 Your symptoms — they show up as buttons under the morning prompt.\n" +
+		"✏️ — rename, ⬆️ — move up, 🗄 — archive (history keeps it), ♻️ — restore from the archive",
+	"btn.sym_add":         "➕ Add",
+	"btn.sym_freq":        "📊 Frequency",
+	"symptoms.ask_add":    "What should the new symptom be called? Up to 32 characters",
+	"symptoms.ask_rename": "New name for \"%s\"?",
+	"symptoms.bad_name":   "The name must be 1 to 32 characters long",
+	"symptoms.exists":     "This symptom is already on the list",
+	"symptoms.full":       "You already have the maximum number of active symptoms — archive some 🗄",
+	"symptoms.gone":       "This symptom is no longer on the list",
+	"symptoms.saved":      "Saved: \"%s\"",
+	"symfreq.title":       "🩺 Symptoms for %d days (%s — %s)",
+	"symfreq.empty":       "No mornings with a check-in in this period",
+	"symfreq.base":        "Mornings with a check-in: %d",
+	"symfreq.row":         "%s — %d (%d%%)",
+
+	// meal diary
+	"meal.breakfast":  "Breakfast",
+	"meal.lunch":      "Lunch",
+	"meal.dinner":     "Dinner",
+	"meal.snack":      "Snack",
+	"tag.fatty":       "fatty",
+	"tag.spicy":       "spicy",
+	"tag.alcohol":     "alcohol",
+	"tag.sweet":       "sweet",
+	"tag.coffee":      "coffee",
+	"tag.dairy":       "dairy",
+	"meal.ask_kind":   "🍽 What are you logging for %s?",
+	"meal.ask_time":   "%s — at what time? HH:MM",
+	"meal.ask_what":   "What did you eat? Send text or a photo with a caption",
+	"meal.ask_tags":   "Tick whatever applies or type your own tag, then “Done”",
+	"meal.bad_what":   "Send a description as text or a photo",
+	"meal.not_today":  "This entry isn't for today — enter the time as HH:MM",
+	"meal.saved":      "Logged: %s",
+	"meal.day":        "🍽 Meals on %s",
+	"meal.empty":      "No entries yet",
+	"btn.now":         "Now",
+	"btn.meal_add":    "➕ Add",
+	"btn.meal_photos": "📷 Photos",
+	"btn.meal_back":   "« Back to day",
+	"tags.bad_name":   "A tag is up to 20 characters, without commas",
+
+	// dinner tags and triggers
+	"dinner.ask_tags":   "What was for dinner? Tick whatever applies or type your own tag, then “Done”",
+	"dinner.tags_saved": "Dinner tagged: %s",
+	"dinner.tags_none":  "OK, dinner without tags",
+	"btn.triggers":      "🍽 Food and complaints",
+	"trig.title":        "🍽 What you ate and how you felt the next morning",
+	"trig.empty":        "Nothing to compare yet. Tag your dinner after “Ate now” or in /meal, and record your wellbeing in the morning.",
+	"trig.base":         "On average you had complaints on %d%% of mornings (%d of %d)",
+	"trig.row":          "after “%s” — complaints on %d%% of mornings vs %d%% on average (%d of %d), ×%.1f",
+	"trig.few":          "Not enough data (need at least %d days): %s",
+
+	// missed prompts
+	"missed.header":        "While the bot was down, I didn't ask these questions:",
+	"missed.footer":        "You can fill them in now or skip them.",
+	"missed.filled":        "%s is already filled in",
+	"missed.skipped.one":   "OK, skipped %d question",
+	"missed.skipped.other": "OK, skipped %d questions",
+
+	// history
+	"history.title":    "📅 History: pick a day\n🔴 — complaints, 🟢 — no complaints, · — dinner only",
+	"history.day":      "📅 %s\n\nMorning wellbeing: %s\nDinner: %s",
+	"history.skipped":  "skipped",
+	"history.missed":   "no answer",
+	"history.edit_m":   "✏️ Wellbeing",
+	"history.edit_e":   "✏️ Dinner",
+	"history.calendar": "« Back to calendar",
+	"history.meals":    "🍽 Meals (%d)",
+
+	// statistics
+	"stats.error":          "Could not compute statistics: %s",
+	"stats.title":          "📊 Statistics for %d days (%s — %s)",
+	"stats.empty":          "No data for this period",
+	"stats.with":           "Days with complaints: %d",
+	"stats.without":        "Days without complaints: %d",
+	"stats.score_avg":      "Average wellbeing: %.1f/%d",
+	"stats.dinner_avg":     "Average dinner: %s",
+	"stats.dinner_median":  "Median dinner: %s",
+	"stats.dinner_last":    "Last dinner: %s",
+	"stats.no_dinners":     "No dinners recorded in this period",
+	"stats.morning":        "Morning answers: %d of %d (%d%%)",
+	"stats.evening":        "Evening answers: %d of %d (%d%%)",
+	"stats.streak":         "Filled-in days streak: %d",
+	"stats.streak_ok":      "Complaint-free days streak: %d",
+	"corr.error":           "Could not build the analysis: %s",
+	"corr.title":           "🍽 Dinner and wellbeing the next morning",
+	"corr.empty":           "No \"dinner → morning answer\" pairs yet. Record dinners and morning wellbeing, and the breakdown will appear here.",
+	"corr.bucket":          "%02d:00–%02d:59 — complaints %d of %d (%d%%)",
+	"corr.early":           "Before %02d:00: %d%% mornings with complaints (%d)",
+	"corr.late":            "From %02d:00: %d%% mornings with complaints (%d)",
+	"corr.few":             "Not enough data: need at least %d pairs in each group",
+	"corr.significant":     "Difference %+.0f pp — statistically significant (p=%.3f)",
+	"corr.random":          "Difference %+.0f pp — may be random (p=%.2f)",
+	"charts.ask":           "Which chart should I draw?",
+	"charts.error":         "Could not draw the chart: %s",
+	"chart.dinner":         "Dinner by day",
+	"chart.heatmap":        "Complaints calendar",
+	"chart.answers":        "Answers by week",
+	"chart.dinner.title":   "Dinner time by day",
+	"chart.complaints":     "complaints",
+	"chart.no_complaints":  "no complaints",
+	"chart.no_data":        "no data",
+	"chart.morning":        "morning",
+	"chart.evening":        "dinner",
+	"report.days":          "Specify the number of days from 1 to %d, e.g. /report 90",
+	"report.error":         "Could not build the report: %s",
+	"report.caption":       "Doctor's report for %d days",
+	"pdf.footer":           "Health diary · page %d",
+	"pdf.title":            "Wellbeing diary",
+	"pdf.period":           "Period: %s — %s",
+	"pdf.generated":        "Generated: %s",
+	"pdf.settings":         "Settings",
+	"pdf.morning_at":       "Morning question: %s",
+	"pdf.evening_at":       "Evening question: %s",
+	"pdf.tz":               "Time zone: %s",
+	"pdf.summary":          "Summary",
+	"pdf.days_total":       "Days in period: %d",
+	"pdf.complaints":       "Days with complaints: %d, without: %d",
+	"pdf.answered":         "Morning filled in: %d of %d, dinner: %d of %d",
+	"pdf.dinner":           "Average dinner: %s, median: %s",
+	"pdf.by_day":           "By day",
+	"pdf.col_day":          "Date",
+	"pdf.col_complaints":   "Morning wellbeing",
+	"pdf.col_dinner":       "Dinner",
+	"pdf.corr_title":       "Dinner and wellbeing the next morning",
+	"pdf.corr_empty":       "Not enough data: no \"dinner → morning answer\" pairs.",
+	"pdf.col_hour":         "Dinner hour",
+	"pdf.col_pairs":        "Pairs",
+	"pdf.col_rate":         "Mornings with complaints",
+	"pdf.corr_groups":      "Dinner before %02d:00: complaints in %.0f%% of mornings (%d), later: %.0f%% (%d)",
+	"pdf.corr_few":         "At least %d pairs in each group are needed to judge significance.",
+	"pdf.corr_significant": "The difference is statistically significant (p = %.3f).",
+	"pdf.corr_random":      "The difference may be random (p = %.2f).",
+
+	// export and import
+	"export.ask_range":    "📤 Diary export: which period?",
+	"export.ask_format":   "📤 Diary export: which format?",
+	"export.all":          "All time",
+	"export.custom":       "Custom range",
+	"export.ask_dates":    "Enter the period as YYYY-MM-DD–YYYY-MM-DD, e.g. 2025-05-01–2025-05-31",
+	"export.bad_dates":    "Could not read the period. Use YYYY-MM-DD–YYYY-MM-DD, start not after end",
+	"export.future_dates": "The period cannot end in the future",
+	"export.error":        "Could not export the diary: %s",
+	"export.empty":        "No records for this period",
+	"export.caption":      "Diary for %s — %s, days with records: %d",
+	"import.help": "📥 Diary import\n\n" +
+		"Send a CSV or JSON file with columns:\n" +
+		"  day — date YYYY-MM-DD\n" +
+		"  complaints — morning wellbeing (may be empty)\n" +
+		"  score — wellbeing score 0–10 (may be empty)\n" +
+		"  symptoms — comma-separated symptom keys as in /export (may be empty)\n" +
+		"  dinner — dinner time HH:MM (may be empty)\n\n" +
+		"CSV — with a header such as day,complaints,dinner, comma or semicolon separated.\n" +
+		"JSON — an array [{\"day\": \"2025-05-08\", \"complaints\": \"no\", \"dinner\": \"19:30\"}].\n" +
+		"/export produces the same files.\n\n" +
+		"I'll show what will change first and save only after you confirm.",
+	"import.only_csv_json":  "Only .csv and .json files can be imported, see /import",
+	"import.too_big":        "The file is too large, 1 MB max",
+	"import.read_error":     "Could not read the file: %s",
+	"import.format_hint":    "File format — /import",
+	"import.btn_apply":      "Import",
+	"import.btn_replace":    "Replace",
+	"import.canceled":       "Import canceled",
+	"import.failed":         "Import failed, the diary is unchanged: %s",
+	"import.done":           "✅ Days imported: %d",
+	"import.download":       "could not download the file",
+	"import.plan":           "📥 File check: %d records",
+	"import.add":            "To be added: %d",
+	"import.same":           "Already in the diary: %d",
+	"import.conflicts":      "Conflicts with the diary: %d",
+	"import.conflicts_hint": "\"Import\" will skip them, \"Replace\" will overwrite them.",
+	"import.problems":       "Skipped with errors: %d",
+	"import.problem":        "• #%d: %s",
+	"import.more":           "…and %d more",
+	"import.nothing":        "Nothing to import.",
+	"import.diff_morning":   " morning \"%s\" → \"%s\"",
+	"import.diff_score":     " score %s → %s",
+	"import.diff_symptoms":  " symptoms %s → %s",
+	"import.diff_dinner":    " dinner %s → %s",
+	"import.no_rows":        "the file has no records",
+	"import.too_many":       "too many rows: %d, %d max",
+	"import.bad_header":     "could not read the header: %s",
+	"import.no_day":         "no day column, expected columns %s",
+	"import.bad_day":        "wrong date \"%s\", expected YYYY-MM-DD",
+	"import.future_day":     "date %s is in the future",
+	"import.bad_dinner":     "wrong dinner time \"%s\", expected HH:MM",
+	"import.bad_score":      "wrong score \"%s\", expected a number from 0 to %d",
+	"import.bad_symptom":    "unknown symptom \"%s\"",
+	"import.empty_row":      "empty record",
+	"import.duplicate":      "day %s already appeared in record #%d",
+	"import.unsupported":    "importing from %q is not supported",
+}
@@ -0,0 +1,149 @@
+// Package i18n — каталоги текстов бота и выбор формы множественного числа.
+//
+// Тексты лежат в ru.go и en.go под одинаковыми ключами. Если в каталоге
+// языка ключа нет, берётся текст из Default, а если нет и там — сам ключ,
+// чтобы пропуск было видно в чате, а не получить пустое сообщение.
+//
+// Формы множественного числа — отдельные ключи с суффиксом формы:
+// "days.one", "days.few", "days.many" для русского, "days.one", "days.other"
+// для английского (см. N).
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang — язык интерфейса, код как в Telegram.
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// Default — язык по умолчанию и запасной каталог.
+const Default = RU
+
+// Langs — поддерживаемые языки в порядке показа в /language.
+var Langs = []Lang{RU, EN}
+
+type catalog struct {
+	msgs   map[string]string
+	plural func(n int) string
+}
+
+var catalogs = map[Lang]catalog{
+	RU: {ru, pluralRU},
+	EN: {en, pluralEN},
+}
+
+// Parse распознаёт поддерживаемый код языка ("ru", "en-US" → en).
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := catalogs[Lang(code)]; ok {
+		return Lang(code), true
+	}
+	return "", false
+}
+
+// Of — язык из сохранённого кода; неизвестный или пустой — Default.
+func Of(code string) Lang {
+	if l, ok := Parse(code); ok {
+		return l
+	}
+	return Default
+}
+
+// FromTelegram выбирает язык по language_code из профиля Telegram.
+// Русскоязычным соседям привычнее русский, остальным — английский.
+func FromTelegram(code string) Lang {
+	if l, ok := Parse(code); ok {
+		return l
+	}
+	switch l, _, _ := strings.Cut(strings.ToLower(code), "-"); l {
+	case "", "uk", "be", "kk", "ky", "uz", "tg", "hy", "az", "ka":
+		return RU
+	default:
+		return EN
+	}
+}
+
+// Name — название языка на нём самом.
+func (l Lang) Name() string {
+	return l.T("lang.name")
+}
+
+// T возвращает текст по ключу; с аргументами — через fmt.Sprintf.
+func (l Lang) T(key string, args ...any) string {
+	msg := l.lookup(key)
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// N — текст в форме множественного числа для n. n передаётся в Sprintf
+// первым аргументом, args — после него.
+func (l Lang) N(key string, n int, args ...any) string {
+	c, ok := catalogs[l]
+	if !ok {
+		c = catalogs[Default]
+	}
+	form := key + "." + c.plural(n)
+	if _, ok := c.msgs[form]; !ok {
+		form = key + ".other"
+	}
+	return l.T(form, append([]any{n}, args...)...)
+}
+
+// List — текст-список через «|», например названия месяцев.
+func (l Lang) List(key string) []string {
+	return strings.Split(l.lookup(key), "|")
+}
+
+// Is — text совпадает с текстом key хотя бы в одном языке. Нужен для
+// кнопок reply-клавиатуры: она могла остаться от прежнего языка.
+func Is(text, key string) bool {
+	for _, l := range Langs {
+		if l.lookup(key) == text {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Lang) lookup(key string) string {
+	if msg, ok := catalogs[l].msgs[key]; ok {
+		return msg
+	}
+	if msg, ok := catalogs[Default].msgs[key]; ok {
+		return msg
+	}
+	return key
+}
+
+// pluralRU: 1, 21 — one; 2–4, 22–24 — few; остальное — many.
+func pluralRU(n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	default:
+		return "many"
+	}
+}
+
+func pluralEN(n int) string {
+	if n == 1 || n == -1 {
+		return "one"
+	}
+	return "other"
+}
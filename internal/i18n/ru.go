@@ -0,0 +1,312 @@
+package i18n
+
+var ru = map[string]string{
+	"lang.name":      "Русский",
+	"lang.ask":       "Выберите язык",
+	"lang.saved":     "Язык интерфейса: русский",
+	"weekdays":       "Пн|Вт|Ср|Чт|Пт|Сб|Вс",
+	"months":         "Январь|Февраль|Март|Апрель|Май|Июнь|Июль|Август|Сентябрь|Октябрь|Ноябрь|Декабрь",
+	"days.one":       "%d день",
+	"days.few":       "%d дня",
+	"days.many":      "%d дней",
+	"days.short":     "%d дн.",
+	"error":          "Ошибка: %s",
+	"no_user":        "Пользователь не найден, отправьте /start",
+	"bad_hm":         "Неверный формат, нужно HH:MM",
+	"bad_time":       "Неверное время, нужно HH:MM",
+	"bad_tz":         "Неверный TZ",
+	"bad_every":      "Нужно число минут от 5 до 120",
+	"bad_max":        "Нужно число от 0 до 10",
+	"bad_quiet":      "Нужен интервал HH:MM-HH:MM или «-»",
+	"btn.yes":        "Да",
+	"btn.cancel":     "Отмена",
+	"btn.change":     "Изменить",
+	"btn.confirm":    "Подтвердить",
+	"btn.skip":       "Пропустить",
+	"btn.skip_all":   "Пропустить все",
+	"callback.stale": "Кнопка устарела, повторите команду",
+	"cancel.done":    "Отменено",
+	"cancel.none":    "Отменять нечего",
+	"flow.expired":   "Вопрос устарел, начните заново",
+
+	// команды и меню
+	"help": "/start — начать\n/stats — статистика\n/correlation — ужин и самочувствие утром\n/charts — графики\n" +
+		"/history — история и правка прошлых дней\n/symptoms — свой список симптомов\n/meal — записать приём пищи\n/export — выгрузить дневник (CSV, JSON, XLSX)\n" +
+		"/import — загрузить историю из CSV или JSON\n/report — PDF-отчёт для врача\n/language — язык\n/cancel — прервать текущий вопрос\n/help — справка",
+	"reset.done":        "База удалена, перезапустите бот",
+	"initial.confirm":   "Перед тем как продолжить работу с ботом, подтвердите настройки",
+	"menu.stats":        "Показать статистику",
+	"menu.morning":      "Задать время утреннего сообщения",
+	"menu.evening":      "Задать время вечернего сообщения",
+	"menu.tz":           "Сменить часовой пояс",
+	"menu.clear":        "Очистить данные",
+	"data.cleared":      "Данные очищены",
+	"start.running":     "Бот уже запущен.\nТекущий стейт: %s\n\nЕсли хотите изменить настройки, отправьте /settings",
+	"state.current":     "Текущий статус: %s",
+	"settings.current":  "Текущие настройки:\nУтро: %s\nВечер: %s\nЧасовой пояс: %s",
+	"settings.saved":    "Настройки сохранены!",
+	"ask.morning_at":    "Введите время утреннего сообщения HH:MM",
+	"ask.evening_at":    "Введите время вечернего сообщения HH:MM",
+	"ask.tz_full":       "Введите часовой пояс (например Europe/Moscow или +3, -05:30, UTC)",
+	"btn.reminders":     "🔔 Напоминания",
+	"settings.remind":   "Напоминания: %s\nТихие часы: %s",
+	"remind.policy":     "каждые %d мин, не больше %s",
+	"remind.off":        "выключены",
+	"times.one":         "%d раза",
+	"times.few":         "%d раз",
+	"times.many":        "%d раз",
+	"quiet.off":         "нет",
+	"ask.remind_every":  "Через сколько минут напоминать о вопросе без ответа? Число от 5 до 120 (сейчас %d)",
+	"ask.remind_max":    "Сколько раз напоминать? От 0 до 10, 0 — не напоминать (сейчас %d)",
+	"ask.quiet":         "Тихие часы, когда бот не напоминает: интервал вроде 23:00-08:00 или «-», чтобы отключить (сейчас %s)",
+	"remind.saved":      "Готово!\n%s",
+	"debug.periods":     "Текущий стейт: %s\nUTC: %s\nЛокальное время: %s (%s)\n\nОкно \"утро\": %s — %s\nОкно \"вечер\": %s — %s\n\nСлед. событие: %s (через %v)",
+	"debug.morning":     "утреннее окно",
+	"debug.morning_end": "конец утреннего окна",
+	"debug.evening":     "вечернее окно",
+	"debug.evening_end": "конец вечернего окна",
+	"debug.tomorrow":    "завтрашнее утро",
+
+	// дневная клавиатура
+	"kb.yesterday_dinner": "Вчера ужинал в …",
+	"kb.today_morning":    "Самочувствие утром",
+	"kb.dinner":           "Ужинал в …",
+	"kb.prev_morning":     "Самочувствие прошлым утром",
+	"kb.meal":             "🍽 Еда",
+
+	// вопросы и ответы
+	"prompt.morning":       "Доброе утро! Как самочувствие? Оцените его от 0 до 10 и отметьте симптомы, если они есть",
+	"prompt.evening.one":   "Пора ужинать! До конца дня остался %d час.",
+	"prompt.evening.few":   "Пора ужинать! До конца дня осталось %d часа.",
+	"prompt.evening.many":  "Пора ужинать! До конца дня осталось %d часов.",
+	"prompt.remind":        "Не забудь ответить 🙂|Напоминаю: жду ответа на вопрос выше 🙏|Последнее напоминание: скоро вопрос закроется",
+	"prompt.expired":       "⌛ Вопрос «%s» закрыт без ответа. Заполнить его можно и позже",
+	"prompt.label.morning": "%s утро",
+	"prompt.label.evening": "%s ужин",
+	"btn.ate_now":          "Поел",
+	"btn.ate_at":           "Поел в …",
+	"btn.snooze_min":       "⏰ %d мин",
+	"btn.snooze_h":         "⏰ %d ч",
+	"btn.skip_today":       "Пропустить сегодня",
+	"prompt.snooze_ok":     "Хорошо, напомню в %s",
+	"prompt.snoozed":       "⏰ Напоминаю, как вы просили: %s",
+	"prompt.skipped":       "Хорошо, %s пропускаем",
+	"ask.dinner":           "Во сколько был ужин (%s)? Введите время HH:MM",
+	"dinner.confirm":       "Сохранить ужин в %s (%s)?",
+	"dinner.saved":         "Время ужина сохранено!",
+	"dinner.enjoy":         "Приятного вечера!",
+
+	// утренний чек-ин
+	"btn.no_complaints": "✅ Нет жалоб",
+	"btn.checkin_note":  "✍️ Добавить текст",
+	"btn.checkin_done":  "💾 Готово",
+	"checkin.ask":       "Самочувствие (%s): оценка от 0 до 10 и симптомы, затем «Готово». Можно просто написать текстом",
+	"checkin.note_ask":  "Опишите самочувствие текстом",
+	"checkin.empty":     "Отметьте оценку, симптомы или «Нет жалоб»",
+	"checkin.saved":     "Спасибо — записал: %s",
+	"checkin.none":      "нет жалоб",
+	"checkin.score":     "самочувствие %d/%d",
+
+	"symptom.heartburn":    "Изжога",
+	"symptom.bloating":     "Вздутие",
+	"symptom.nausea":       "Тошнота",
+	"symptom.belching":     "Отрыжка",
+	"symptom.stomach_pain": "Боль в животе",
+	"symptom.headache":     "Головная боль",
+	"symptom.poor_sleep":   "Плохой сон",
+
+	// свой список симптомов
+	"symptoms.title": "🩺 Ваши симптомы — они появляются кнопками под утренним вопросом.\n" +
+		"✏️ — переименовать, ⬆️ — поднять выше, 🗄 — в архив (в истории симптом останется), ♻️ — вернуть из архива",
+	"btn.sym_add":         "➕ Добавить",
+	"btn.sym_freq":        "📊 Частота",
+	"symptoms.ask_add":    "Как назвать новый симптом? Не длиннее 32 символов",
+	"symptoms.ask_rename": "Новое название для «%s»?",
+	"symptoms.bad_name":   "Название должно быть от 1 до 32 символов",
+	"symptoms.exists":     "Такой симптом уже есть в списке",
+	"symptoms.full":       "Активных симптомов уже максимум — уберите лишние в архив 🗄",
+	"symptoms.gone":       "Этого симптома уже нет в списке",
+	"symptoms.saved":      "Сохранил: «%s»",
+	"symfreq.title":       "🩺 Симптомы за %d дн. (%s — %s)",
+	"symfreq.empty":       "За этот период нет утр с отметками",
+	"symfreq.base":        "Утр с отметками: %d",
+	"symfreq.row":         "%s — %d (%d%%)",
+
+	// дневник питания
+	"meal.breakfast":  "Завтрак",
+	"meal.lunch":      "Обед",
+	"meal.dinner":     "Ужин",
+	"meal.snack":      "Перекус",
+	"tag.fatty":       "жирное",
+	"tag.spicy":       "острое",
+	"tag.alcohol":     "алкоголь",
+	"tag.sweet":       "сладкое",
+	"tag.coffee":      "кофе",
+	"tag.dairy":       "молочное",
+	"meal.ask_kind":   "🍽 Что записать за %s?",
+	"meal.ask_time":   "%s — во сколько? Время HH:MM",
+	"meal.ask_what":   "Что ели? Напишите текстом или пришлите фото с подписью",
+	"meal.ask_tags":   "Отметьте подходящее или напишите свой тег, затем «Готово»",
+	"meal.bad_what":   "Пришлите описание текстом или фото",
+	"meal.not_today":  "Запись не за сегодня — введите время HH:MM",
+	"meal.saved":      "Записал: %s",
+	"meal.day":        "🍽 Еда за %s",
+	"meal.empty":      "Записей пока нет",
+	"btn.now":         "Сейчас",
+	"btn.meal_add":    "➕ Добавить",
+	"btn.meal_photos": "📷 Фото",
+	"btn.meal_back":   "« К дню",
+	"tags.bad_name":   "Тег — до 20 символов и без запятых",
+
+	// теги ужина и триггеры
+	"dinner.ask_tags":   "Что было на ужин? Отметьте подходящее или напишите свой тег, затем «Готово»",
+	"dinner.tags_saved": "Ужин отмечен: %s",
+	"dinner.tags_none":  "Хорошо, ужин без отметок",
+	"btn.triggers":      "🍽 Еда и жалобы",
+	"trig.title":        "🍽 Что вы ели и самочувствие следующим утром",
+	"trig.empty":        "Пока не с чем сравнивать. Отмечайте, что было на ужин, после «Поел» или в /meal, а утром — самочувствие.",
+	"trig.base":         "В среднем жалобы были %d%% утр (%d из %d)",
+	"trig.row":          "после «%s» — жалобы %d%% утр против %d%% в среднем (%d из %d), ×%.1f",
+	"trig.few":          "Мало данных (нужно хотя бы %d дней): %s",
+
+	// пропущенные вопросы
+	"missed.header":       "Пока бот не работал, я не задал эти вопросы:",
+	"missed.footer":       "Можно заполнить их сейчас или пропустить.",
+	"missed.filled":       "%s уже заполнено",
+	"missed.skipped.one":  "Хорошо, пропущен %d вопрос",
+	"missed.skipped.few":  "Хорошо, пропущено %d вопроса",
+	"missed.skipped.many": "Хорошо, пропущено %d вопросов",
+
+	// история
+	"history.title":    "📅 История: выберите день\n🔴 — жалобы, 🟢 — без жалоб, · — только ужин",
+	"history.day":      "📅 %s\n\nСамочувствие утром: %s\nУжин: %s",
+	"history.skipped":  "пропущено",
+	"history.missed":   "нет ответа",
+	"history.edit_m":   "✏️ Самочувствие",
+	"history.edit_e":   "✏️ Ужин",
+	"history.calendar": "« К календарю",
+	"history.meals":    "🍽 Еда (%d)",
+
+	// статистика
+	"stats.error":          "Не удалось посчитать статистику: %s",
+	"stats.title":          "📊 Статистика за %d дн. (%s — %s)",
+	"stats.empty":          "За этот период данных нет",
+	"stats.with":           "Дней с жалобами: %d",
+	"stats.without":        "Дней без жалоб: %d",
+	"stats.score_avg":      "Среднее самочувствие: %.1f/%d",
+	"stats.dinner_avg":     "Средний ужин: %s",
+	"stats.dinner_median":  "Медиана ужина: %s",
+	"stats.dinner_last":    "Последний ужин: %s",
+	"stats.no_dinners":     "Ужины за период не отмечены",
+	"stats.morning":        "Ответы утром: %d из %d (%d%%)",
+	"stats.evening":        "Ответы вечером: %d из %d (%d%%)",
+	"stats.streak":         "Серия заполненных дней: %d",
+	"stats.streak_ok":      "Серия дней без жалоб: %d",
+	"corr.error":           "Не удалось построить анализ: %s",
+	"corr.title":           "🍽 Ужин и самочувствие следующим утром",
+	"corr.empty":           "Пока нет пар «ужин → ответ утром». Отмечайте ужин и утреннее самочувствие, и здесь появится разбивка.",
+	"corr.bucket":          "%02d:00–%02d:59 — жалобы %d из %d (%d%%)",
+	"corr.early":           "До %02d:00: %d%% утр с жалобами (%d)",
+	"corr.late":            "С %02d:00: %d%% утр с жалобами (%d)",
+	"corr.few":             "Данных мало: нужно хотя бы по %d пар в каждой группе",
+	"corr.significant":     "Разница %+.0f п.п. — статистически значима (p=%.3f)",
+	"corr.random":          "Разница %+.0f п.п. — может быть случайной (p=%.2f)",
+	"charts.ask":           "Какой график построить?",
+	"charts.error":         "Не удалось построить график: %s",
+	"chart.dinner":         "Ужин по дням",
+	"chart.heatmap":        "Календарь жалоб",
+	"chart.answers":        "Ответы по неделям",
+	"chart.dinner.title":   "Время ужина по дням",
+	"chart.complaints":     "жалобы",
+	"chart.no_complaints":  "без жалоб",
+	"chart.no_data":        "нет данных",
+	"chart.morning":        "утро",
+	"chart.evening":        "ужин",
+	"report.days":          "Укажите число дней от 1 до %d, например /report 90",
+	"report.error":         "Не удалось собрать отчёт: %s",
+	"report.caption":       "Отчёт для врача за %d дн.",
+	"pdf.footer":           "Дневник здоровья · стр. %d",
+	"pdf.title":            "Дневник самочувствия",
+	"pdf.period":           "Период: %s — %s",
+	"pdf.generated":        "Сформирован: %s",
+	"pdf.settings":         "Настройки",
+	"pdf.morning_at":       "Утренний вопрос: %s",
+	"pdf.evening_at":       "Вечерний вопрос: %s",
+	"pdf.tz":               "Часовой пояс: %s",
+	"pdf.summary":          "Сводка",
+	"pdf.days_total":       "Дней в периоде: %d",
+	"pdf.complaints":       "Дней с жалобами: %d, без жалоб: %d",
+	"pdf.answered":         "Заполнено утро: %d из %d, ужин: %d из %d",
+	"pdf.dinner":           "Ужин в среднем: %s, медиана: %s",
+	"pdf.by_day":           "По дням",
+	"pdf.col_day":          "Дата",
+	"pdf.col_complaints":   "Самочувствие утром",
+	"pdf.col_dinner":       "Ужин",
+	"pdf.corr_title":       "Ужин и самочувствие следующим утром",
+	"pdf.corr_empty":       "Недостаточно данных: нет пар «ужин → ответ утром».",
+	"pdf.col_hour":         "Час ужина",
+	"pdf.col_pairs":        "Пар",
+	"pdf.col_rate":         "Утро с жалобами",
+	"pdf.corr_groups":      "Ужин до %02d:00: жалобы в %.0f%% утр (%d), позже: %.0f%% (%d)",
+	"pdf.corr_few":         "Для вывода о значимости нужно не меньше %d пар в каждой группе.",
+	"pdf.corr_significant": "Разница статистически значима (p = %.3f).",
+	"pdf.corr_random":      "Разница может быть случайной (p = %.2f).",
+
+	// экспорт и импорт
+	"export.ask_range":    "📤 Экспорт дневника: за какой период?",
+	"export.ask_format":   "📤 Экспорт дневника: в каком формате?",
+	"export.all":          "Всё время",
+	"export.custom":       "Свой период",
+	"export.ask_dates":    "Введите период как ГГГГ-ММ-ДД–ГГГГ-ММ-ДД, например 2025-05-01–2025-05-31",
+	"export.bad_dates":    "Не понял период. Нужно ГГГГ-ММ-ДД–ГГГГ-ММ-ДД, начало не позже конца",
+	"export.future_dates": "Период не может заканчиваться в будущем",
+	"export.error":        "Не удалось выгрузить дневник: %s",
+	"export.empty":        "За этот период записей нет",
+	"export.caption":      "Дневник за %s — %s, дней с записями: %d",
+	"import.help": "📥 Импорт дневника\n\n" +
+		"Пришлите файл CSV или JSON с колонками:\n" +
+		"  day — дата YYYY-MM-DD\n" +
+		"  complaints — самочувствие утром (можно пусто)\n" +
+		"  score — оценка самочувствия 0–10 (можно пусто)\n" +
+		"  symptoms — ключи симптомов через запятую, как в /export (можно пусто)\n" +
+		"  dinner — время ужина HH:MM (можно пусто)\n\n" +
+		"CSV — с заголовком, например day,complaints,dinner, разделитель запятая или точка с запятой.\n" +
+		"JSON — массив [{\"day\": \"2025-05-08\", \"complaints\": \"нет\", \"dinner\": \"19:30\"}].\n" +
+		"Такие же файлы делает /export.\n\n" +
+		"Сначала покажу, что изменится, и запишу только после подтверждения.",
+	"import.only_csv_json":  "Импортировать можно только .csv и .json, подробнее — /import",
+	"import.too_big":        "Файл слишком большой, максимум 1 МБ",
+	"import.read_error":     "Не удалось прочитать файл: %s",
+	"import.format_hint":    "Формат файла — /import",
+	"import.btn_apply":      "Импортировать",
+	"import.btn_replace":    "С заменой",
+	"import.canceled":       "Импорт отменён",
+	"import.failed":         "Импорт не выполнен, дневник не изменён: %s",
+	"import.done":           "✅ Импортировано дней: %d",
+	"import.download":       "файл не скачался",
+	"import.plan":           "📥 Проверка файла: записей %d",
+	"import.add":            "Будет добавлено: %d",
+	"import.same":           "Уже есть в дневнике: %d",
+	"import.conflicts":      "Конфликтов с дневником: %d",
+	"import.conflicts_hint": "«Импортировать» их пропустит, «С заменой» — перезапишет.",
+	"import.problems":       "Пропущено с ошибками: %d",
+	"import.problem":        "• №%d: %s",
+	"import.more":           "…и ещё %d",
+	"import.nothing":        "Импортировать нечего.",
+	"import.diff_morning":   " утро «%s» → «%s»",
+	"import.diff_score":     " оценка %s → %s",
+	"import.diff_symptoms":  " симптомы %s → %s",
+	"import.diff_dinner":    " ужин %s → %s",
+	"import.no_rows":        "в файле нет записей",
+	"import.too_many":       "слишком много строк: %d, максимум %d",
+	"import.bad_header":     "не удалось прочитать заголовок: %s",
+	"import.no_day":         "нет колонки day, ожидаются колонки %s",
+	"import.bad_day":        "неверная дата «%s», нужно YYYY-MM-DD",
+	"import.future_day":     "дата %s ещё не наступила",
+	"import.bad_dinner":     "неверное время ужина «%s», нужно HH:MM",
+	"import.bad_score":      "неверная оценка «%s», нужно число от 0 до %d",
+	"import.bad_symptom":    "неизвестный симптом «%s»",
+	"import.empty_row":      "пустая запись",
+	"import.duplicate":      "день %s уже был в записи №%d",
+	"import.unsupported":    "импорт из %q не поддерживается",
+}
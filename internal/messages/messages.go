@@ -1,47 +1,215 @@
 package messages
 
 import (
+	"slices"
 	"strconv"
+	"strings"
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/callback"
+	"telegram-health-dairy/internal/i18n"
 	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
 	"telegram-health-dairy/internal/storage"
-	"telegram-health-dairy/internal/utils"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-const (
-	btnAteNow = "Поел"
-	btnAteAt  = "Поел в …"
-)
+// SnoozeOptions — на сколько минут можно отложить вопрос
+var SnoozeOptions = []int{30, 60, 120}
+
+// MorningKB — кнопки под утренним вопросом dateKey: чек-ин с симптомами
+// из catalog и «отложить»
+func MorningKB(l i18n.Lang, dateKey string, catalog []models.Symptom) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(append(checkInRows(l, dateKey, catalog, nil), snoozeRows(l, dateKey)...)...)
+}
+
+// CheckInKB — кнопки чек-ина; c — что уже отмечено (nil — ничего)
+func CheckInKB(l i18n.Lang, dateKey string, catalog []models.Symptom, c *models.CheckIn) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(checkInRows(l, dateKey, catalog, c)...)
+}
+
+// checkInRows — «нет жалоб», оценка 0–10 в два ряда, активные симптомы
+// по два в ряд, текст и «готово»
+func checkInRows(l i18n.Lang, dateKey string, catalog []models.Symptom, c *models.CheckIn) [][]tgbotapi.InlineKeyboardButton {
+	if c == nil {
+		c = &models.CheckIn{}
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(callback.Button(l.T("btn.no_complaints"), callback.CheckNone, dateKey, "")),
+	}
+
+	var score []tgbotapi.InlineKeyboardButton
+	for v := 0; v <= models.MaxScore; v++ {
+		label := strconv.Itoa(v)
+		if c.Score != nil && *c.Score == v {
+			label = "•" + label + "•"
+		}
+		score = append(score, callback.Button(label, callback.CheckScore, dateKey, strconv.Itoa(v)))
+	}
+	half := (models.MaxScore + 2) / 2
+	rows = append(rows, score[:half], score[half:])
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, s := range catalog {
+		if s.Archived {
+			continue
+		}
+		label := stats.SymptomName(l, s)
+		if slices.Contains(c.Symptoms, s.Key) {
+			label = "✔️ " + label
+		}
+		row = append(row, callback.Button(label, callback.CheckSymptom, dateKey, s.Key))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	return append(rows, tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.checkin_note"), callback.CheckNote, dateKey, ""),
+		callback.Button(l.T("btn.checkin_done"), callback.CheckDone, dateKey, ""),
+	))
+}
 
-func SendEvening(bot *tgbotapi.BotAPI, db *storage.DB, u *models.User, dateKey string) error {
-	hrs := hoursLeft(u)
-	txt := "Пора ужинать! До конца дня осталось " + strconv.Itoa(hrs) + " ч."
-	kb := tgbotapi.NewInlineKeyboardMarkup(
+// EveningKB — кнопки под вечерним вопросом dateKey
+func EveningKB(l i18n.Lang, dateKey string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(append([][]tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(btnAteNow, btnAteNow),
-			tgbotapi.NewInlineKeyboardButtonData(btnAteAt, btnAteAt),
+			callback.Button(l.T("btn.ate_now"), callback.AteNow, dateKey, ""),
+			callback.Button(l.T("btn.ate_at"), callback.AteAt, dateKey, ""),
 		),
-	)
+	}, snoozeRows(l, dateKey)...)...)
+}
 
-	msg := tgbotapi.NewMessage(u.ChatID, txt)
-	msg.ReplyMarkup = kb
+// snoozeRows — «напомнить через …» и «пропустить сегодня»
+func snoozeRows(l i18n.Lang, dateKey string) [][]tgbotapi.InlineKeyboardButton {
+	var snooze []tgbotapi.InlineKeyboardButton
+	for _, m := range SnoozeOptions {
+		snooze = append(snooze, callback.Button(SnoozeLabel(l, m), callback.Snooze, dateKey, strconv.Itoa(m)))
+	}
+	return [][]tgbotapi.InlineKeyboardButton{
+		snooze,
+		tgbotapi.NewInlineKeyboardRow(
+			callback.Button(l.T("btn.skip_today"), callback.SkipToday, dateKey, ""),
+		),
+	}
+}
+
+// SnoozeLabel: 30 → "⏰ 30 мин", 120 → "⏰ 2 ч"
+func SnoozeLabel(l i18n.Lang, minutes int) string {
+	if minutes%60 == 0 {
+		return l.T("btn.snooze_h", minutes/60)
+	}
+	return l.T("btn.snooze_min", minutes)
+}
+
+// SendMorning задаёт утренний вопрос и запоминает его как pending,
+// заданный в now. Не отправилось — pending не создаётся.
+func SendMorning(bot bot.Sender, db storage.Store, u *models.User, dateKey string, now time.Time) error {
+	l := i18n.Of(u.Lang)
+	msg := tgbotapi.NewMessage(u.ChatID, l.T("prompt.morning"))
+	catalog, _ := db.ListSymptoms(u.ChatID)
+	msg.ReplyMarkup = MorningKB(l, dateKey, catalog)
 	m, err := bot.Send(msg)
-	utils.LogFor(err)
+	if err != nil {
+		return err
+	}
+
+	return db.InsertPending(&models.PendingMessage{
+		ChatID:    u.ChatID,
+		DateKey:   dateKey,
+		Type:      "morning",
+		MsgID:     m.MessageID,
+		CreatedAt: now.Unix(),
+	})
+}
+
+// SendEvening задаёт вечерний вопрос; now — время пользователя, от него
+// считаем, сколько часов осталось до конца дня.
+func SendEvening(bot bot.Sender, db storage.Store, u *models.User, dateKey string, now time.Time) error {
+	l := i18n.Of(u.Lang)
+	msg := tgbotapi.NewMessage(u.ChatID, l.N("prompt.evening", 23-now.Hour()))
+	msg.ReplyMarkup = EveningKB(l, dateKey)
+	m, err := bot.Send(msg)
+	if err != nil {
+		return err
+	}
 
 	return db.InsertPending(&models.PendingMessage{
 		ChatID:    u.ChatID,
 		DateKey:   dateKey,
 		Type:      "evening",
 		MsgID:     m.MessageID,
-		CreatedAt: time.Now().Unix(),
+		CreatedAt: now.Unix(),
 	})
 }
 
-func hoursLeft(u *models.User) int {
-	loc, _ := time.LoadLocation(u.TZ)
-	now := time.Now().In(loc)
-	end := time.Date(now.Year(), now.Month(), now.Day(), 23, 0, 0, 0, loc)
-	return int(end.Sub(now).Hours())
+// maxMissedButtons — больше кнопок в одном сообщении только мешают
+const maxMissedButtons = 6
+
+// SendMissed шлёт одно сводное сообщение о вопросах, которые бот не задал,
+// пока не работал: у каждого кнопки «заполнить задним числом» и
+// «пропустить», внизу — «пропустить все».
+func SendMissed(bot bot.Sender, l i18n.Lang, chatID int64, dateKeys []string) error {
+	var b strings.Builder
+	b.WriteString(l.T("missed.header") + "\n")
+	for _, key := range dateKeys {
+		b.WriteString("• " + PromptLabel(l, key) + "\n")
+	}
+	b.WriteString("\n" + l.T("missed.footer"))
+
+	// кнопки — для самых свежих дней
+	fill := dateKeys
+	if len(fill) > maxMissedButtons {
+		fill = fill[len(fill)-maxMissedButtons:]
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, key := range fill {
+		rows = append(rows, missedRow(l, key))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		callback.Button(l.T("btn.skip_all"), callback.MissedSkip, "", ""),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err := bot.Send(msg)
+	return err
+}
+
+// SendExpired сообщает, что вопрос dateKey закрыт без ответа, и предлагает
+// заполнить его задним числом или пропустить.
+func SendExpired(bot bot.Sender, l i18n.Lang, chatID int64, dateKey string) error {
+	msg := tgbotapi.NewMessage(chatID, l.T("prompt.expired", PromptLabel(l, dateKey)))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(missedRow(l, dateKey))
+	_, err := bot.Send(msg)
+	return err
+}
+
+// missedRow — «✏️ 8 мая, утро» и «Пропустить» для вопроса dateKey
+func missedRow(l i18n.Lang, dateKey string) []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		callback.Button("✏️ "+PromptLabel(l, dateKey), callback.MissedFill, dateKey, ""),
+		callback.Button(l.T("btn.skip"), callback.MissedSkip, dateKey, ""),
+	)
+}
+
+// PromptLabel: "2025-05-08-morning" → "08.05 утро"
+func PromptLabel(l i18n.Lang, dateKey string) string {
+	if len(dateKey) < 11 {
+		return dateKey
+	}
+	day, kind := dateKey[:10], dateKey[11:]
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return dateKey
+	}
+	if kind == "evening" {
+		return l.T("prompt.label.evening", t.Format("02.01"))
+	}
+	return l.T("prompt.label.morning", t.Format("02.01"))
 }
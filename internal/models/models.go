@@ -9,9 +9,22 @@ type User struct {
 	TZ        string `db:"tz"         json:"tz"`
 	MorningAt string `db:"morning_at" json:"morning_at"` // "HH:MM"
 	EveningAt string `db:"evening_at" json:"evening_at"` // "HH:MM"
+	Lang      string `db:"lang"       json:"lang"`       // "ru", "en"; см. i18n
 	CreatedAt int64  `db:"created_at" json:"created_at"`
+
+	// политика напоминаний о вопросе без ответа
+	RemindEvery int    `db:"remind_every" json:"remind_every"` // минут между напоминаниями
+	RemindMax   int    `db:"remind_max"   json:"remind_max"`   // сколько раз напомнить; 0 — не напоминать
+	QuietFrom   string `db:"quiet_from"   json:"quiet_from"`   // "HH:MM"; пусто — тихих часов нет
+	QuietTo     string `db:"quiet_to"     json:"quiet_to"`     // "HH:MM", может быть раньше QuietFrom (через полночь)
 }
 
+// Политика напоминаний по умолчанию.
+const (
+	DefaultRemindEvery = 20
+	DefaultRemindMax   = 3
+)
+
 // DayRecord stores daily complaints & dinner info.
 type DayRecord struct {
 	ID         int64      `db:"id"`
@@ -19,8 +32,43 @@ type DayRecord struct {
 	Day        string     `db:"day"`                 // YYYY-MM-DD
 	Complaints string     `db:"complaints"`          // empty -> no complaints
 	DinnerAt   *time.Time `db:"dinner_at,omitempty"` // nil -> not set
+	CheckIn    *CheckIn   `db:"-"`                   // nil -> answered by text only or not at all
+	DinnerTags []string   `db:"-"`                   // что было на ужин, см. MealTags
+}
+
+// MorningAnswered — на утренний вопрос ответили: кнопками или текстом.
+func (r DayRecord) MorningAnswered() bool {
+	return r.CheckIn != nil || r.Complaints != ""
+}
+
+// CheckIn — утренний ответ кнопками; свободный текст остаётся в
+// DayRecord.Complaints.
+type CheckIn struct {
+	NoComplaints bool     // нажата «Нет жалоб»
+	Score        *int     // самочувствие 0–10; nil — не оценено
+	Symptoms     []string // ключи симптомов из списка пользователя
 }
 
+// Symptom — симптом из списка пользователя.
+type Symptom struct {
+	Key      string // значение в CheckIn.Symptoms
+	Name     string // "" — встроенный симптом, название из перевода
+	Position int
+	Archived bool // в архиве: не предлагается в чек-ине, но остаётся в истории
+}
+
+// DefaultSymptoms — встроенные симптомы, с ними начинается список
+// пользователя; названия — в i18n под ключами "symptom.<ключ>".
+var DefaultSymptoms = []string{
+	"heartburn", "bloating", "nausea", "belching", "stomach_pain", "headache", "poor_sleep",
+}
+
+// MaxSymptoms — сколько активных симптомов помещается под вопросом.
+const MaxSymptoms = 12
+
+// MaxScore — верхняя граница оценки самочувствия.
+const MaxScore = 10
+
 // PendingMessage tracks messages waiting for reply.
 type PendingMessage struct {
 	ID         int64  `db:"id"`
@@ -30,10 +78,61 @@ type PendingMessage struct {
 	MsgID      int    `db:"msg_id"`      // ID исходного сообщения-вопроса
 	CreatedAt  int64  `db:"created_at"`  // когда вопрос был отправлен
 	RemindedAt int64  `db:"reminded_at"` // когда в последний раз напомнили (0 = ещё не напоминали)
+	Reminders  int    `db:"reminders"`   // сколько напоминаний уже отправлено
+	// SnoozeUntil — пользователь попросил напомнить не раньше (unix; 0 — не
+	// просил). Просьба выполнена, когда RemindedAt >= SnoozeUntil.
+	SnoozeUntil int64 `db:"snooze_until"`
+}
+
+// Snoozed — ждём отложенного напоминания.
+func (p *PendingMessage) Snoozed() bool {
+	return p.SnoozeUntil > p.RemindedAt
 }
 
-// UserState stores transient FSM states (waiting text input)
-type UserState struct {
-	ChatID int64  `db:"chat_id"`
-	State  string `db:"state"`
+// Conversation — незаконченный диалог с пользователем (см. fsm).
+// Хранится JSON в user_states.
+type Conversation struct {
+	Flow    string            `json:"flow"`
+	Step    string            `json:"step"`
+	Key     string            `json:"key,omitempty"`     // о чём диалог, например 2025-05-08-morning
+	Data    map[string]string `json:"data,omitempty"`    // ответы по шагам
+	Expires int64             `json:"expires,omitempty"` // unix; 0 — без таймаута
 }
+
+// PromptMark — судьба вопроса, на который нет ответа.
+type PromptMark struct {
+	ChatID    int64  `db:"chat_id"`
+	DateKey   string `db:"date_key"`   // 2025-05-08-morning / …-evening
+	Status    string `db:"status"`     // MarkMissed либо MarkSkipped
+	CreatedAt int64  `db:"created_at"` // когда отметили
+}
+
+const (
+	MarkMissed  = "missed"  // вопрос не был задан вовремя (бот не работал)
+	MarkSkipped = "skipped" // пользователь сам решил не заполнять
+)
+
+// Meal — приём пищи из дневника питания.
+type Meal struct {
+	ID      int64
+	ChatID  int64
+	Day     string // YYYY-MM-DD по времени пользователя
+	At      time.Time
+	Kind    string // MealBreakfast, MealLunch, …
+	Text    string
+	PhotoID string   // file_id фото в Telegram; "" — без фото
+	Tags    []string // ключи из MealTags
+}
+
+// Виды приёмов пищи; названия — в i18n под ключами "meal.<вид>".
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+var MealKinds = []string{MealBreakfast, MealLunch, MealDinner, MealSnack}
+
+// MealTags — теги еды; названия — в i18n под ключами "tag.<ключ>".
+var MealTags = []string{"fatty", "spicy", "alcohol", "sweet", "coffee", "dairy"}
@@ -0,0 +1,212 @@
+// Package report собирает PDF-отчёт для визита к врачу: настройки,
+// сводку за период, таблицу дней и связь ужина с самочувствием утром.
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/stats"
+)
+
+// Data — всё, что попадает в отчёт.
+type Data struct {
+	Lang        i18n.Lang
+	User        *models.User
+	TZ          string // как показывать пояс пользователю, например GMT+3
+	Loc         *time.Location
+	Since       time.Time // начало дневника, см. stats.Build
+	Records     []models.DayRecord
+	Symptoms    []models.Symptom // список пользователя — названия симптомов
+	Summary     stats.Report
+	Correlation stats.Correlation
+	Generated   time.Time
+}
+
+const (
+	font    = "Go"
+	margin  = 15.0
+	lineH   = 5.0
+	colDay  = 28.0
+	colDin  = 22.0
+	tableFS = 9.0
+)
+
+// PDF рендерит отчёт. Шрифты Go встроены в бинарник и покрывают кириллицу,
+// так что внешние файлы и сервисы не нужны.
+func PDF(d Data) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddUTF8FontFromBytes(font, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(font, "B", gobold.TTF)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-10)
+		pdf.SetFont(font, "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 5, d.Lang.T("pdf.footer", pdf.PageNo()), "", 0, "C", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.AddPage()
+
+	title(pdf, d)
+	summary(pdf, d.Lang, d.Summary)
+	days(pdf, d)
+	correlation(pdf, d.Lang, d.Correlation)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func heading(pdf *fpdf.Fpdf, text string) {
+	pdf.Ln(4)
+	pdf.SetFont(font, "B", 13)
+	pdf.CellFormat(0, 8, text, "", 1, "L", false, 0, "")
+	pdf.SetFont(font, "", 10)
+}
+
+func line(pdf *fpdf.Fpdf, text string) {
+	pdf.CellFormat(0, lineH+1, text, "", 1, "L", false, 0, "")
+}
+
+func title(pdf *fpdf.Fpdf, d Data) {
+	l := d.Lang
+	pdf.SetFont(font, "B", 18)
+	pdf.CellFormat(0, 10, l.T("pdf.title"), "", 1, "L", false, 0, "")
+	pdf.SetFont(font, "", 10)
+	line(pdf, l.T("pdf.period", dmy(d.Summary.From), dmy(d.Summary.To)))
+	line(pdf, l.T("pdf.generated", d.Generated.In(d.Loc).Format("02.01.2006 15:04")))
+
+	heading(pdf, l.T("pdf.settings"))
+	line(pdf, l.T("pdf.morning_at", d.User.MorningAt))
+	line(pdf, l.T("pdf.evening_at", d.User.EveningAt))
+	line(pdf, l.T("pdf.tz", d.TZ))
+}
+
+func summary(pdf *fpdf.Fpdf, l i18n.Lang, r stats.Report) {
+	heading(pdf, l.T("pdf.summary"))
+	line(pdf, l.T("pdf.days_total", r.Days))
+	line(pdf, l.T("pdf.complaints", r.WithComplaints, r.NoComplaints))
+	line(pdf, l.T("pdf.answered", r.MorningAnswered, r.Days, r.EveningAnswered, r.Days))
+	if r.EveningAnswered > 0 {
+		line(pdf, l.T("pdf.dinner", stats.Clock(r.DinnerAvg), stats.Clock(r.DinnerMedian)))
+	}
+}
+
+// days — таблица всех дней периода; дни с жалобами подсвечены
+func days(pdf *fpdf.Fpdf, d Data) {
+	l := d.Lang
+	heading(pdf, l.T("pdf.by_day"))
+	weekdays := l.List("weekdays")
+
+	byDay := make(map[string]models.DayRecord, len(d.Records))
+	for _, rec := range d.Records {
+		byDay[rec.Day] = rec
+	}
+
+	pageW, pageH := pdf.GetPageSize()
+	colCmp := pageW - 2*margin - colDay - colDin
+
+	header := func() {
+		pdf.SetFont(font, "B", tableFS)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(colDay, 7, l.T("pdf.col_day"), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(colCmp, 7, l.T("pdf.col_complaints"), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(colDin, 7, l.T("pdf.col_dinner"), "1", 1, "C", true, 0, "")
+		pdf.SetFont(font, "", tableFS)
+	}
+	header()
+
+	from, _ := time.ParseInLocation("2006-01-02", d.Summary.From, d.Loc)
+	to, _ := time.ParseInLocation("2006-01-02", d.Summary.To, d.Loc)
+	if since := d.Since.In(d.Loc); since.After(from) {
+		from = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, d.Loc)
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		rec := byDay[key]
+
+		complaints, dinner := stats.Morning(d.Lang, rec, d.Symptoms), "—"
+		if complaints == "" {
+			complaints = "—"
+		}
+		if rec.DinnerAt != nil {
+			dinner = rec.DinnerAt.In(d.Loc).Format("15:04")
+		}
+		bad := rec.MorningAnswered() && !stats.Fine(rec)
+
+		lines := pdf.SplitText(complaints, colCmp-2)
+		rowH := math.Max(float64(len(lines))*lineH, lineH) + 2
+
+		if pdf.GetY()+rowH > pageH-margin-5 {
+			pdf.AddPage()
+			header()
+		}
+
+		if bad {
+			pdf.SetFillColor(253, 226, 226)
+		} else {
+			pdf.SetFillColor(255, 255, 255)
+		}
+		x, y := pdf.GetXY()
+		label := day.Format("02.01") + " " + weekdays[(int(day.Weekday())+6)%7]
+		pdf.CellFormat(colDay, rowH, label, "1", 0, "L", true, 0, "")
+		pdf.Rect(x+colDay, y, colCmp, rowH, "FD")
+		pdf.SetXY(x+colDay, y+1)
+		pdf.MultiCell(colCmp, lineH, complaints, "", "L", false)
+		pdf.SetXY(x+colDay+colCmp, y)
+		pdf.CellFormat(colDin, rowH, dinner, "1", 1, "C", true, 0, "")
+	}
+}
+
+func correlation(pdf *fpdf.Fpdf, l i18n.Lang, c stats.Correlation) {
+	heading(pdf, l.T("pdf.corr_title"))
+	if len(c.Buckets) == 0 {
+		line(pdf, l.T("pdf.corr_empty"))
+		return
+	}
+
+	pdf.SetFont(font, "B", tableFS)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(40, 7, l.T("pdf.col_hour"), "1", 0, "L", true, 0, "")
+	pdf.CellFormat(30, 7, l.T("pdf.col_pairs"), "1", 0, "C", true, 0, "")
+	pdf.CellFormat(40, 7, l.T("pdf.col_rate"), "1", 1, "C", true, 0, "")
+	pdf.SetFont(font, "", tableFS)
+	for _, b := range c.Buckets {
+		pdf.CellFormat(40, 6, fmt.Sprintf("%02d:00–%02d:59", b.Hour, b.Hour), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 6, fmt.Sprint(b.Total), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(40, 6, fmt.Sprintf("%.0f%%", b.Rate()*100), "1", 1, "C", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont(font, "", 10)
+	line(pdf, l.T("pdf.corr_groups",
+		stats.LateDinnerHour, c.Early.Rate()*100, c.Early.Total, c.Late.Rate()*100, c.Late.Total))
+	switch {
+	case math.IsNaN(c.PValue):
+		line(pdf, l.T("pdf.corr_few", stats.MinGroupSize))
+	case c.Significant():
+		line(pdf, l.T("pdf.corr_significant", c.PValue))
+	default:
+		line(pdf, l.T("pdf.corr_random", c.PValue))
+	}
+}
+
+func dmy(day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return day
+	}
+	return t.Format("02.01.2006")
+}
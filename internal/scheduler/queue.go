@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"container/heap"
+	"time"
+)
+
+// Виды событий пользователя — совпадают с PendingMessage.Type.
+const (
+	kindMorning = "morning"
+	kindEvening = "evening"
+)
+
+// event — ближайший утренний или вечерний вопрос конкретного пользователя.
+type event struct {
+	chatID int64
+	kind   string
+	hour   int       // время из настроек — от него считается следующее событие,
+	min    int       // даже если at сдвинут переходом на летнее время
+	at     time.Time // момент в часовом поясе пользователя
+	retry  time.Time // повтор вопроса at, который не удалось задать; ноль — нет
+	index  int       // позиция в куче, нужна для heap.Remove
+}
+
+// when — когда событие срабатывает: по расписанию или повтором
+func (e *event) when() time.Time {
+	if !e.retry.IsZero() {
+		return e.retry
+	}
+	return e.at
+}
+
+// eventQueue — min-heap по времени срабатывания.
+type eventQueue []*event
+
+func (q eventQueue) Len() int           { return len(q) }
+func (q eventQueue) Less(i, j int) bool { return q[i].when().Before(q[j].when()) }
+
+func (q eventQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *eventQueue) Push(x any) {
+	e := x.(*event)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*q = old[:n-1]
+	return e
+}
+
+var _ heap.Interface = (*eventQueue)(nil)
+
+// parseHM разбирает "HH:MM" (допускается и "9:00").
+func parseHM(hm string) (hour, min int, err error) {
+	t, err := time.Parse("15:04", hm)
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// nextAt — ближайший момент hh:mm в loc строго после after.
+// Несуществующее при переходе на летнее время значение time.Date
+// сдвигает вперёд, так что событие в этот день не теряется.
+func nextAt(hour, min int, loc *time.Location, after time.Time) time.Time {
+	a := after.In(loc)
+	t := time.Date(a.Year(), a.Month(), a.Day(), hour, min, 0, 0, loc)
+	if !t.After(after) {
+		t = time.Date(a.Year(), a.Month(), a.Day()+1, hour, min, 0, 0, loc)
+	}
+	return t
+}
+
+// prevAt — последний момент hh:mm в loc, не позже now.
+func prevAt(hour, min int, loc *time.Location, now time.Time) time.Time {
+	n := now.In(loc)
+	t := time.Date(n.Year(), n.Month(), n.Day(), hour, min, 0, 0, loc)
+	if t.After(now) {
+		t = time.Date(n.Year(), n.Month(), n.Day()-1, hour, min, 0, 0, loc)
+	}
+	return t
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/messages"
+	"telegram-health-dairy/internal/models"
+)
+
+// startReminders — минутная job для «допинывания»: напоминает о вопросах без
+// ответа по политике пользователя и закрывает вопросы, окно которых прошло.
+func (s *Scheduler) startReminders() (gocron.Scheduler, error) {
+	c, err := gocron.NewScheduler(gocron.WithClock(s.clock))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = c.NewJob(gocron.DurationJob(1*time.Minute), gocron.NewTask(s.remindAll))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Start()
+	return c, nil
+}
+
+func (s *Scheduler) remindAll() {
+	now := s.clock.Now()
+	chats, _ := s.db.ListChatsByState(models.StateWaitingMorning, models.StateWaitingEvening)
+
+	for _, chatID := range chats {
+		u, err := s.db.GetUser(chatID)
+		if err != nil || u == nil {
+			continue
+		}
+		l := i18n.Of(u.Lang)
+		pendings, _ := s.db.ListPending(chatID)
+		for _, p := range pendings {
+			switch {
+			case p.Snoozed() && now.Unix() < p.SnoozeUntil:
+				// отложили — ни напоминаний, ни закрытия
+			case p.Snoozed():
+				// просьбу выполняем и в тихие часы, и сверх лимита
+				s.remind(u, p, l.T("prompt.snoozed", messages.PromptLabel(l, p.DateKey)), now)
+			case now.Sub(windowStart(p)) >= s.grace:
+				s.expire(u, p, now)
+			case remindDue(u, p, now):
+				s.remind(u, p, remindText(l, p.Reminders, u.RemindMax), now)
+			}
+		}
+	}
+}
+
+// remind отвечает text на вопрос p и запоминает, что напомнили
+func (s *Scheduler) remind(u *models.User, p models.PendingMessage, text string, now time.Time) {
+	reply := tgbotapi.NewMessage(u.ChatID, text)
+	reply.ReplyToMessageID = p.MsgID
+	if _, err := s.bot.Send(reply); err != nil {
+		log.Printf("⚠️ scheduler: chat %d: напоминание: %v", u.ChatID, err)
+		return
+	}
+	s.db.TouchReminder(p.ID, now)
+}
+
+// windowStart — откуда отсчитывается окно ответа: отложенный вопрос
+// получает полное окно после отложенного напоминания
+func windowStart(p models.PendingMessage) time.Time {
+	return time.Unix(max(p.CreatedAt, p.SnoozeUntil), 0)
+}
+
+// expire закрывает вопрос, окно которого (Grace) прошло без ответа: он
+// отмечается missed, и его можно заполнить задним числом. Сессия
+// возвращается в idle, иначе следующий вопрос не будет задан. В тихие
+// часы вопрос закрывается молча: об этом, как и о напоминаниях, не пишем.
+func (s *Scheduler) expire(u *models.User, p models.PendingMessage, now time.Time) {
+	if err := s.db.DeletePending(u.ChatID, p.DateKey); err != nil {
+		log.Printf("⚠️ scheduler: chat %d: DeletePending: %v", u.ChatID, err)
+		return
+	}
+	s.db.MarkPrompt(u.ChatID, p.DateKey, models.MarkMissed)
+
+	waiting := models.StateWaitingMorning
+	if p.Type == kindEvening {
+		waiting = models.StateWaitingEvening
+	}
+	if st, _ := s.db.GetSessionState(u.ChatID); st == waiting {
+		s.db.SetSessionState(u.ChatID, models.StateIdle)
+	}
+
+	if quietHours(u, now) {
+		return
+	}
+	if err := messages.SendExpired(s.bot, i18n.Of(u.Lang), u.ChatID, p.DateKey); err != nil {
+		log.Printf("⚠️ scheduler: chat %d: %v", u.ChatID, err)
+	}
+}
+
+// remindDue — пора напомнить о p: лимит не исчерпан, с прошлого напоминания
+// (или с вопроса) прошёл интервал и сейчас не тихие часы.
+func remindDue(u *models.User, p models.PendingMessage, now time.Time) bool {
+	if p.Reminders >= u.RemindMax {
+		return false
+	}
+	every := u.RemindEvery
+	if every <= 0 {
+		every = models.DefaultRemindEvery
+	}
+	if now.Sub(time.Unix(p.RemindedAt, 0)) < time.Duration(every)*time.Minute {
+		return false
+	}
+	return !quietHours(u, now)
+}
+
+// quietHours — now попадает в [QuietFrom, QuietTo) по времени пользователя;
+// интервал может переходить через полночь (23:00–08:00).
+func quietHours(u *models.User, now time.Time) bool {
+	fh, fm, err := parseHM(u.QuietFrom)
+	if err != nil {
+		return false
+	}
+	th, tm, err := parseHM(u.QuietTo)
+	if err != nil {
+		return false
+	}
+	loc, err := tzToLocation(u.TZ)
+	if err != nil {
+		return false
+	}
+	local := now.In(loc)
+	m := local.Hour()*60 + local.Minute()
+	from, to := fh*60+fm, th*60+tm
+	if from <= to {
+		return m >= from && m < to
+	}
+	return m >= from || m < to
+}
+
+// remindText — текст n-го напоминания (с нуля): каждое следующее настойчивее,
+// последнее предупреждает, что вопрос скоро закроется.
+func remindText(l i18n.Lang, n, limit int) string {
+	texts := l.List("prompt.remind")
+	if len(texts) < 2 {
+		return texts[0]
+	}
+	if n >= limit-1 {
+		return texts[len(texts)-1]
+	}
+	return texts[min(n, len(texts)-2)]
+}
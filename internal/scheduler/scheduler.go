@@ -1,133 +1,387 @@
 package scheduler
 
 import (
+	"container/heap"
 	"errors"
+	"log"
 	"math"
 	"regexp"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-co-op/gocron/v2"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/clock"
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/messages"
 	"telegram-health-dairy/internal/models"
 	"telegram-health-dairy/internal/storage"
 )
 
-var eveningKB = tgbotapi.NewInlineKeyboardMarkup(
-	tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("Поел", "Поел"),
-		tgbotapi.NewInlineKeyboardButtonData("Поел в …", "Поел в …"),
-	),
-)
+// DefaultGrace — сколько после назначенного времени вопрос ещё имеет смысл
+// задать (совпадает с длиной утреннего/вечернего окна).
+const DefaultGrace = 2 * time.Hour
+
+// retryDelay — через сколько повторить вопрос, который не удалось задать:
+// пользователь ещё отвечает на предыдущий или Telegram не принял сообщение.
+const retryDelay = 5 * time.Minute
+
+// DefaultLookback — за сколько последних дней ищем незаданные вопросы при старте.
+const DefaultLookback = 3
+
+// Options — настройки планировщика; нулевые значения заменяются на Default*.
+type Options struct {
+	Grace    time.Duration
+	Lookback int         // дней
+	Clock    clock.Clock // nil — системные часы
+}
+
+// Scheduler держит для каждого пользователя ближайшие утреннее и вечернее
+// события в очереди с приоритетом и спит до ближайшего из них.
+// Минутная gocron-задача осталась только для напоминаний.
+// Больше **не** импортирует пакет handlers → нет циклической зависимости.
+type Scheduler struct {
+	bot      bot.Sender
+	db       storage.Store
+	clock    clock.Clock
+	grace    time.Duration
+	lookback int
+	cron     gocron.Scheduler
 
-// Start — запускает минутный cron‑таск.
-// Больше **не** импортирует пакет handlers → нет циклической зависимости.
-func Start(bot *tgbotapi.BotAPI, db *storage.DB) (gocron.Scheduler, error) {
-	s, err := gocron.NewScheduler()
+	mu     sync.Mutex
+	queue  eventQueue
+	byChat map[int64][]*event
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Start загружает всех пользователей, досылает вопросы, пропущенные не более
+// чем Grace назад (например, пока бот был выключен), и запускает цикл.
+// Более старые незаданные вопросы собираются в одно сообщение (см. reconcile).
+func Start(bot bot.Sender, db storage.Store, opts Options) (*Scheduler, error) {
+	if opts.Grace <= 0 {
+		opts.Grace = DefaultGrace
+	}
+	if opts.Lookback <= 0 {
+		opts.Lookback = DefaultLookback
+	}
+	if opts.Clock == nil {
+		opts.Clock = clock.Real()
+	}
+	s := &Scheduler{
+		bot:      bot,
+		db:       db,
+		clock:    opts.Clock,
+		grace:    opts.Grace,
+		lookback: opts.Lookback,
+		byChat:   map[int64][]*event{},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	users, err := db.ListUsers()
 	if err != nil {
 		return nil, err
 	}
+	now := s.clock.Now()
+	for _, u := range users {
+		s.schedule(&u, now, true)
+	}
+
+	if s.cron, err = s.startReminders(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		s.reconcile(users, now)
+		s.loop()
+	}()
+	return s, nil
+}
+
+// Reschedule пересчитывает события пользователя после смены настроек.
+func (s *Scheduler) Reschedule(chatID int64) {
+	u, err := s.db.GetUser(chatID)
+	if err != nil {
+		log.Printf("⚠️ scheduler: GetUser(%d): %v", chatID, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.remove(chatID)
+	if u != nil {
+		s.schedule(u, s.clock.Now(), false)
+	}
+	s.mu.Unlock()
+	s.poke()
+}
+
+// Remove забывает пользователя (например, после очистки данных).
+func (s *Scheduler) Remove(chatID int64) {
+	s.mu.Lock()
+	s.remove(chatID)
+	s.mu.Unlock()
+	s.poke()
+}
+
+// Shutdown останавливает цикл событий и напоминания, дожидаясь текущей отправки.
+func (s *Scheduler) Shutdown() error {
+	close(s.stop)
+	<-s.done
+	return s.cron.Shutdown()
+}
+
+// schedule кладёт в очередь ближайшие события пользователя.
+// С catchUp событие, наступившее не более grace назад, ставится на «сейчас».
+// Вызывается под s.mu (или до запуска цикла).
+func (s *Scheduler) schedule(u *models.User, now time.Time, catchUp bool) {
+	loc, err := tzToLocation(u.TZ)
+	if err != nil {
+		log.Printf("⚠️ scheduler: chat %d: неизвестный TZ %q", u.ChatID, u.TZ)
+		return
+	}
+
+	for _, kind := range []string{kindMorning, kindEvening} {
+		hm := u.MorningAt
+		if kind == kindEvening {
+			hm = u.EveningAt
+		}
+		hour, min, err := parseHM(hm)
+		if err != nil {
+			log.Printf("⚠️ scheduler: chat %d: неверное время %q", u.ChatID, hm)
+			continue
+		}
+
+		at := nextAt(hour, min, loc, now)
+		if catchUp {
+			if prev := prevAt(hour, min, loc, now); now.Sub(prev) <= s.grace {
+				at = prev
+			}
+		}
+
+		e := &event{chatID: u.ChatID, kind: kind, hour: hour, min: min, at: at}
+		heap.Push(&s.queue, e)
+		s.byChat[u.ChatID] = append(s.byChat[u.ChatID], e)
+	}
+}
+
+// remove вызывается под s.mu
+func (s *Scheduler) remove(chatID int64) {
+	for _, e := range s.byChat[chatID] {
+		if e.index >= 0 {
+			heap.Remove(&s.queue, e.index)
+		}
+	}
+	delete(s.byChat, chatID)
+}
+
+// poke будит цикл, чтобы он пересчитал время сна
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
 
-	// Вторая минутная job для «допинывания»
-	_, _ = s.NewJob(
-		gocron.DurationJob(1*time.Minute),
-		gocron.NewTask(func() {
-			rows, _ := db.Query(`SELECT chat_id FROM sessions
-                             WHERE state IN ('waiting_morning','waiting_evening')`)
-			defer rows.Close()
+// reconcile ищет за последние lookback дней вопросы, которые так и не были
+// заданы (время прошло больше grace назад, ответа, pending и отметки нет),
+// отмечает их missed и шлёт каждому пользователю одно сводное сообщение.
+// Благодаря отметкам повторный старт про те же дни не напоминает.
+func (s *Scheduler) reconcile(users []models.User, now time.Time) {
+	for _, u := range users {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
 
-			for rows.Next() {
-				var chatID int64
-				rows.Scan(&chatID)
+		st, err := s.db.GetSessionState(u.ChatID)
+		if err != nil || st == models.StateNotStarted || st == models.StateInitial {
+			continue // настройку ещё не прошёл — спрашивать было не о чем
+		}
+		loc, err := tzToLocation(u.TZ)
+		if err != nil {
+			continue
+		}
 
-				pendings, _ := db.ListPendingForReminder(chatID)
-				for _, p := range pendings {
-					txt := "Не забудь ответить 🙂"
-					reply := tgbotapi.NewMessage(chatID, txt)
-					reply.ReplyToMessageID = p.MsgID
-					bot.Send(reply)
+		missed := s.findMissed(&u, loc, now)
+		if len(missed) == 0 {
+			continue
+		}
+		for _, key := range missed {
+			s.db.MarkPrompt(u.ChatID, key, models.MarkMissed)
+		}
+		if err := messages.SendMissed(s.bot, i18n.Of(u.Lang), u.ChatID, missed); err != nil {
+			log.Printf("⚠️ scheduler: chat %d: %v", u.ChatID, err)
+		}
+	}
+}
 
-					db.TouchReminder(p.ID)
-				}
+func (s *Scheduler) findMissed(u *models.User, loc *time.Location, now time.Time) []string {
+	created := time.Unix(u.CreatedAt, 0)
+	local := now.In(loc)
+
+	var keys []string
+	for i := s.lookback; i >= 0; i-- {
+		day := time.Date(local.Year(), local.Month(), local.Day()-i, 0, 0, 0, 0, loc)
+		for _, kind := range []string{kindMorning, kindEvening} {
+			hm := u.MorningAt
+			if kind == kindEvening {
+				hm = u.EveningAt
 			}
-		}),
-	)
-
-	_, err = s.NewJob(
-		gocron.DurationJob(1*time.Minute),
-		gocron.NewTask(func() {
-			rows, err := db.Query(`
-    			SELECT u.chat_id, u.tz, u.morning_at, u.evening_at
-    			FROM users AS u
-			    LEFT JOIN sessions AS s ON s.chat_id = u.chat_id
-    			WHERE COALESCE(s.state, 'idle') = 'idle'
-			`)
+			hour, min, err := parseHM(hm)
 			if err != nil {
-				return
+				continue
+			}
+			at := time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, loc)
+			if at.Before(created) || now.Sub(at) <= s.grace {
+				continue // ещё не был зарегистрирован или успеет catch-up
+			}
+
+			key := day.Format("2006-01-02") + "-" + kind
+			if s.db.HasPendingOrAnswered(u.ChatID, key) {
+				continue
+			}
+			if mark, _ := s.db.GetPromptMark(u.ChatID, key); mark != "" {
+				continue
 			}
-			defer rows.Close()
-
-			for rows.Next() {
-				var chatID int64
-				var tz, morning, evening string
-				_ = rows.Scan(&chatID, &tz, &morning, &evening)
-
-				loc, err := tzToLocation(tz)
-				if err != nil {
-					continue
-				}
-
-				now := time.Now().In(loc)
-				day := now.Format("2006-01-02")
-
-				// ---------- утро ----------
-				if now.Format("15:04") == morning {
-					key := day + "-morning"
-					if !db.HasPending(chatID, key) {
-						msg := tgbotapi.NewMessage(chatID, "Доброе утро! Опишите своё самочувствие")
-						sent, _ := bot.Send(msg)
-
-						db.InsertPending(&models.PendingMessage{
-							ChatID:    chatID,
-							DateKey:   key,
-							Type:      "morning",
-							MsgID:     sent.MessageID,
-							CreatedAt: time.Now().Unix(),
-						})
-						db.SetSessionState(chatID, models.StateWaitingMorning)
-					}
-				}
-
-				// ---------- вечер ----------
-				if now.Format("15:04") == evening {
-					key := day + "-evening"
-					if !db.HasPending(chatID, key) {
-						hrsLeft := 23 - now.Hour()
-						txt := "Пора ужинать! До конца дня осталось " + strconv.Itoa(hrsLeft) + " ч."
-						msg := tgbotapi.NewMessage(chatID, txt)
-						msg.ReplyMarkup = eveningKB
-						sent, _ := bot.Send(msg)
-
-						db.InsertPending(&models.PendingMessage{
-							ChatID:    chatID,
-							DateKey:   key,
-							Type:      "evening",
-							MsgID:     sent.MessageID,
-							CreatedAt: time.Now().Unix(),
-						})
-						db.SetSessionState(chatID, models.StateWaitingEvening)
-					}
-				}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		s.mu.Lock()
+		sleep := time.Hour // пустая очередь — просто ждём пинка
+		if len(s.queue) > 0 {
+			sleep = s.clock.Until(s.queue[0].when())
+		}
+		s.mu.Unlock()
+
+		timer := s.clock.NewTimer(sleep)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.Chan():
+			s.fireDue()
+		}
+	}
+}
+
+// fireDue снимает с очереди все наступившие события, отправляет вопросы
+// и ставит следующие события тех же пользователей. Вопрос, который не
+// удалось задать, повторяется через retryDelay, пока не выйдет окно grace.
+func (s *Scheduler) fireDue() {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	var due []*event
+	for len(s.queue) > 0 && !s.queue[0].when().After(now) {
+		due = append(due, heap.Pop(&s.queue).(*event))
+	}
+	s.mu.Unlock()
+
+	for _, e := range due {
+		select {
+		case <-s.stop:
+			return // остальное досошлёт catch-up при следующем старте
+		default:
+		}
+
+		// процесс мог «проспать» (suspend, долгая пауза) — устаревшее не шлём
+		retry := false
+		if now.Sub(e.at) <= s.grace {
+			retry = s.fire(e)
+		}
+
+		s.mu.Lock()
+		if s.owns(e) {
+			next := *e
+			next.retry = time.Time{}
+			if at := now.Add(retryDelay); retry && at.Sub(e.at) <= s.grace {
+				next.retry = at
+			} else {
+				next.at = nextAt(e.hour, e.min, e.at.Location(), now)
 			}
-		}),
-	)
+			heap.Push(&s.queue, &next)
+			s.replace(e, &next)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// owns — событие всё ещё актуально (не было Reschedule/Remove, пока слали)
+func (s *Scheduler) owns(e *event) bool {
+	for _, x := range s.byChat[e.chatID] {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) replace(old, next *event) {
+	evs := s.byChat[old.chatID]
+	for i, x := range evs {
+		if x == old {
+			evs[i] = next
+		}
+	}
+}
+
+// fire задаёт вопрос, если пользователь сейчас ничего не заполняет
+// и за этот день вопрос ещё не задавался. Если вопрос не ушёл (бот
+// заблокирован, 429), состояние не меняется. true — задать не удалось,
+// но стоит повторить: пользователь занят другим вопросом или отправка
+// не прошла.
+func (s *Scheduler) fire(e *event) bool {
+	st, err := s.db.GetSessionState(e.chatID)
 	if err != nil {
-		return nil, err
+		return true
+	}
+	switch st {
+	case models.StateIdle, models.StateNotStarted:
+	case models.StateWaitingMorning, models.StateWaitingEvening:
+		return true
+	default:
+		return false
 	}
 
-	s.Start()
-	return s, nil
+	key := e.at.Format("2006-01-02") + "-" + e.kind
+	if s.db.HasPendingOrAnswered(e.chatID, key) {
+		return false
+	}
+
+	u, err := s.db.GetUser(e.chatID)
+	if err != nil || u == nil {
+		return false
+	}
+
+	state := models.StateWaitingMorning
+	send := messages.SendMorning
+	now := s.clock.Now()
+	if e.kind == kindEvening {
+		state, send = models.StateWaitingEvening, messages.SendEvening
+		now = now.In(e.at.Location())
+	}
+	if err := send(s.bot, s.db, u, key, now); err != nil {
+		log.Printf("⚠️ scheduler: chat %d: вопрос %s: %v", e.chatID, key, err)
+		return true
+	}
+	s.db.SetSessionState(e.chatID, state)
+	return false
 }
 
 var offRx = regexp.MustCompile(`^(?i)(gmt|utc)?([+-]\d{1,2})(?::?(\d{2}))?$`)
@@ -0,0 +1,128 @@
+package scheduler_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/clock"
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/scheduler"
+	"telegram-health-dairy/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const chatID = 42
+
+// flaky не принимает первые fails сообщений, как Telegram при 429
+type flaky struct {
+	*bot.Fake
+	fails atomic.Int32
+}
+
+func (f *flaky) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	if f.fails.Add(-1) >= 0 {
+		return tgbotapi.Message{}, errors.New("Too Many Requests: retry after 30")
+	}
+	return f.Fake.Send(c)
+}
+
+type env struct {
+	t   *testing.T
+	clk *clock.Fake
+	db  *storage.DB
+}
+
+// newEnv — пользователь с вопросами в 17:00 и 18:00 UTC и планировщик,
+// запущенный в start
+func newEnv(t *testing.T, b bot.Sender, start time.Time) *env {
+	t.Helper()
+	clk := clock.NewFake(start)
+	db, err := storage.Open(storage.DriverSQLite, filepath.Join(t.TempDir(), "bot.db"))
+	if err != nil {
+		t.Fatalf("storage.Open: %v", err)
+	}
+	db.SetClock(clk)
+	err = db.UpsertUser(&models.User{ChatID: chatID, TZ: "UTC", MorningAt: "17:00", EveningAt: "18:00"})
+	if err != nil {
+		t.Fatalf("UpsertUser: %v", err)
+	}
+	db.SetSessionState(chatID, models.StateIdle)
+
+	s, err := scheduler.Start(b, db, scheduler.Options{Clock: clk})
+	if err != nil {
+		t.Fatalf("scheduler.Start: %v", err)
+	}
+	t.Cleanup(func() {
+		s.Shutdown()
+		db.Close()
+	})
+	return &env{t: t, clk: clk, db: db}
+}
+
+// advanceTo двигает часы поминутно, давая циклу планировщика отработать
+func (e *env) advanceTo(to time.Time) {
+	for e.clk.Now().Before(to) {
+		e.clk.Advance(time.Minute)
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+// awaitPending ждёт, пока вопрос key будет задан
+func (e *env) awaitPending(key string, want bool) {
+	e.t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if e.db.HasPending(chatID, key) == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	e.t.Fatalf("HasPending(%s) = %v; want %v", key, !want, want)
+}
+
+// TestEveningWaitsForMorning — вечерний вопрос, пока открыт утренний, не
+// теряется, а задаётся после ответа на утренний
+func TestEveningWaitsForMorning(t *testing.T) {
+	e := newEnv(t, bot.NewFake(), time.Date(2025, 5, 8, 16, 59, 0, 0, time.UTC))
+
+	e.advanceTo(time.Date(2025, 5, 8, 17, 0, 0, 0, time.UTC))
+	e.awaitPending("2025-05-08-morning", true)
+
+	e.advanceTo(time.Date(2025, 5, 8, 18, 0, 0, 0, time.UTC))
+	time.Sleep(20 * time.Millisecond)
+	if e.db.HasPending(chatID, "2025-05-08-evening") {
+		t.Fatal("вечерний вопрос задан поверх открытого утреннего")
+	}
+
+	// ответ на утренний вопрос
+	e.db.UpsertDayRecord(chatID, "2025-05-08", "нет")
+	e.db.DeletePending(chatID, "2025-05-08-morning")
+	e.db.SetSessionState(chatID, models.StateIdle)
+
+	e.advanceTo(time.Date(2025, 5, 8, 18, 10, 0, 0, time.UTC))
+	e.awaitPending("2025-05-08-evening", true)
+	if st, _ := e.db.GetSessionState(chatID); st != models.StateWaitingEvening {
+		t.Errorf("state = %s; want waiting_evening", st)
+	}
+}
+
+// TestRetryAfterSendError — вопрос, который Telegram не принял, повторяется
+func TestRetryAfterSendError(t *testing.T) {
+	b := &flaky{Fake: bot.NewFake()}
+	b.fails.Store(2)
+	e := newEnv(t, b, time.Date(2025, 5, 8, 16, 59, 0, 0, time.UTC))
+
+	e.advanceTo(time.Date(2025, 5, 8, 17, 0, 0, 0, time.UTC))
+	time.Sleep(20 * time.Millisecond)
+	if e.db.HasPending(chatID, "2025-05-08-morning") {
+		t.Fatal("вопрос задан, хотя отправка не прошла")
+	}
+
+	e.advanceTo(time.Date(2025, 5, 8, 17, 15, 0, 0, time.UTC))
+	e.awaitPending("2025-05-08-morning", true)
+}
@@ -0,0 +1,150 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+)
+
+// LateDinnerHour — ужин с этого часа (и после полуночи) считаем поздним.
+const LateDinnerHour = 20
+
+// MinGroupSize — меньше пар в группе сравнивать бессмысленно.
+const MinGroupSize = 5
+
+// Bucket — ужины, начавшиеся в один и тот же час.
+type Bucket struct {
+	Hour       int // 0‥23 по времени пользователя
+	Total      int // пар «ужин → ответ следующим утром»
+	Complaints int // из них утро с жалобами
+}
+
+func (b Bucket) Rate() float64 {
+	if b.Total == 0 {
+		return 0
+	}
+	return float64(b.Complaints) / float64(b.Total)
+}
+
+// Correlation связывает ужин дня N с жалобами утром дня N+1.
+type Correlation struct {
+	Buckets []Bucket // по возрастанию часа, вечер → ночь
+	Early   Bucket   // ужин до LateDinnerHour
+	Late    Bucket   // ужин в LateDinnerHour и позже
+	Diff    float64  // Late.Rate() - Early.Rate()
+	PValue  float64  // двусторонний z-тест двух долей, NaN если данных мало
+}
+
+// Significant — разница статистически значима на уровне 5%.
+func (c Correlation) Significant() bool {
+	return !math.IsNaN(c.PValue) && c.PValue < 0.05
+}
+
+// Correlate строит разбивку по часу ужина. Пары, где утро не заполнено,
+// не учитываются — «не ответил» не равно «нет жалоб».
+func Correlate(recs []models.DayRecord, loc *time.Location) Correlation {
+	byDay := make(map[string]models.DayRecord, len(recs))
+	for _, rec := range recs {
+		byDay[rec.Day] = rec
+	}
+
+	hours := map[int]*Bucket{}
+	var c Correlation
+	for _, rec := range recs {
+		if rec.DinnerAt == nil {
+			continue
+		}
+		day, err := time.ParseInLocation(dayLayout, rec.Day, loc)
+		if err != nil {
+			continue
+		}
+		next, ok := byDay[day.AddDate(0, 0, 1).Format(dayLayout)]
+		if !ok || !next.MorningAnswered() {
+			continue
+		}
+		bad := !Fine(next)
+
+		dinner := rec.DinnerAt.In(loc)
+		b := hours[dinner.Hour()]
+		if b == nil {
+			b = &Bucket{Hour: dinner.Hour()}
+			hours[dinner.Hour()] = b
+		}
+		group := &c.Early
+		if SinceMidnight(dinner) >= LateDinnerHour*time.Hour {
+			group = &c.Late
+		}
+		for _, g := range []*Bucket{b, group} {
+			g.Total++
+			if bad {
+				g.Complaints++
+			}
+		}
+	}
+
+	for _, b := range hours {
+		c.Buckets = append(c.Buckets, *b)
+	}
+	sort.Slice(c.Buckets, func(i, j int) bool {
+		return eveningOrder(c.Buckets[i].Hour) < eveningOrder(c.Buckets[j].Hour)
+	})
+
+	c.Diff = c.Late.Rate() - c.Early.Rate()
+	c.PValue = twoProportionP(c.Early, c.Late)
+	return c
+}
+
+// eveningOrder ставит ночные часы (00‥03) после 23.
+func eveningOrder(h int) int {
+	if h < 4 {
+		return h + 24
+	}
+	return h
+}
+
+// twoProportionP — p-value двустороннего z-теста для двух долей.
+func twoProportionP(a, b Bucket) float64 {
+	if a.Total < MinGroupSize || b.Total < MinGroupSize {
+		return math.NaN()
+	}
+	n1, n2 := float64(a.Total), float64(b.Total)
+	pooled := float64(a.Complaints+b.Complaints) / (n1 + n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/n1 + 1/n2))
+	if se == 0 {
+		return 1 // в обеих группах одно и то же — разницы нет
+	}
+	z := (b.Rate() - a.Rate()) / se
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// FormatCorrelation рендерит разбивку для отправки в чат.
+func FormatCorrelation(l i18n.Lang, c Correlation) string {
+	var b strings.Builder
+
+	b.WriteString(l.T("corr.title") + "\n\n")
+	if len(c.Buckets) == 0 {
+		b.WriteString(l.T("corr.empty"))
+		return b.String()
+	}
+
+	for _, bk := range c.Buckets {
+		b.WriteString(l.T("corr.bucket", bk.Hour, bk.Hour, bk.Complaints, bk.Total, percent(bk.Complaints, bk.Total)) + "\n")
+	}
+
+	b.WriteString("\n" + l.T("corr.early", LateDinnerHour, percent(c.Early.Complaints, c.Early.Total), c.Early.Total) + "\n")
+	b.WriteString(l.T("corr.late", LateDinnerHour, percent(c.Late.Complaints, c.Late.Total), c.Late.Total) + "\n")
+
+	switch {
+	case math.IsNaN(c.PValue):
+		b.WriteString("\n" + l.T("corr.few", MinGroupSize))
+	case c.Significant():
+		b.WriteString("\n" + l.T("corr.significant", c.Diff*100, c.PValue))
+	default:
+		b.WriteString("\n" + l.T("corr.random", c.Diff*100, c.PValue))
+	}
+	return b.String()
+}
@@ -0,0 +1,285 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+)
+
+// Period — длина отчётного периода в днях.
+type Period int
+
+const (
+	Week    Period = 7
+	Month   Period = 30
+	Quarter Period = 90
+)
+
+// Periods — периоды, между которыми можно переключаться кнопками.
+var Periods = []Period{Week, Month, Quarter}
+
+const dayLayout = "2006-01-02"
+
+// Report — сводка по дневнику за период.
+type Report struct {
+	Period Period
+	From   string // YYYY-MM-DD
+	To     string // YYYY-MM-DD
+	Days   int    // дней в периоде с начала дневника
+
+	WithComplaints int
+	NoComplaints   int
+
+	MorningAnswered int
+	EveningAnswered int
+
+	Scored   int     // утр с оценкой самочувствия
+	ScoreAvg float64 // средняя оценка, 0–10
+
+	DinnerAvg    time.Duration // от полуночи
+	DinnerMedian time.Duration // от полуночи
+	LastDinner   *time.Time
+
+	AnswerStreak       int // дней подряд с заполненным утром
+	NoComplaintsStreak int // дней подряд без жалоб
+}
+
+// Range возвращает границы периода, заканчивающегося сегодняшним днём.
+func Range(p Period, now time.Time) (from, to string) {
+	return now.AddDate(0, 0, -int(p)+1).Format(dayLayout), now.Format(dayLayout)
+}
+
+// Build считает отчёт по записям recs (в любом порядке).
+// now должен быть в часовом поясе пользователя, since — начало дневника:
+// регистрация или первый импортированный день, если он раньше.
+func Build(recs []models.DayRecord, p Period, since, now time.Time) Report {
+	from, to := Range(p, now)
+	r := Report{Period: p, From: from, To: to}
+
+	loc := now.Location()
+	first := since.In(loc).Format(dayLayout)
+	if first > from {
+		from = first
+	}
+
+	byDay := make(map[string]models.DayRecord, len(recs))
+	for _, rec := range recs {
+		byDay[rec.Day] = rec
+	}
+
+	var dinners []time.Duration
+	for d := now; ; d = d.AddDate(0, 0, -1) {
+		day := d.Format(dayLayout)
+		if day < from {
+			break
+		}
+		r.Days++
+
+		rec, ok := byDay[day]
+		if !ok {
+			continue
+		}
+		if rec.MorningAnswered() {
+			r.MorningAnswered++
+			if Fine(rec) {
+				r.NoComplaints++
+			} else {
+				r.WithComplaints++
+			}
+		}
+		if rec.CheckIn != nil && rec.CheckIn.Score != nil {
+			r.Scored++
+			r.ScoreAvg += float64(*rec.CheckIn.Score)
+		}
+		if rec.DinnerAt != nil {
+			r.EveningAnswered++
+			dinners = append(dinners, SinceMidnight(rec.DinnerAt.In(loc)))
+			if r.LastDinner == nil || rec.DinnerAt.After(*r.LastDinner) {
+				t := rec.DinnerAt.In(loc)
+				r.LastDinner = &t
+			}
+		}
+	}
+
+	if r.Scored > 0 {
+		r.ScoreAvg /= float64(r.Scored)
+	}
+	if len(dinners) > 0 {
+		var sum time.Duration
+		for _, v := range dinners {
+			sum += v
+		}
+		r.DinnerAvg = sum / time.Duration(len(dinners))
+
+		sort.Slice(dinners, func(i, j int) bool { return dinners[i] < dinners[j] })
+		mid := len(dinners) / 2
+		if len(dinners)%2 == 0 {
+			r.DinnerMedian = (dinners[mid-1] + dinners[mid]) / 2
+		} else {
+			r.DinnerMedian = dinners[mid]
+		}
+	}
+
+	r.AnswerStreak = streak(byDay, now, first, models.DayRecord.MorningAnswered)
+	r.NoComplaintsStreak = streak(byDay, now, first, Fine)
+	return r
+}
+
+// streak считает дни подряд, для которых выполняется ok, начиная с сегодня.
+// Сегодняшний день без ответа серию не обрывает — он ещё не закончился.
+func streak(byDay map[string]models.DayRecord, now time.Time, first string, ok func(models.DayRecord) bool) int {
+	n := 0
+	for d := now; ; d = d.AddDate(0, 0, -1) {
+		day := d.Format(dayLayout)
+		if day < first {
+			return n
+		}
+		rec, found := byDay[day]
+		if found && ok(rec) {
+			n++
+			continue
+		}
+		if d.Equal(now) && (!found || !rec.MorningAnswered()) {
+			continue
+		}
+		return n
+	}
+}
+
+// SinceMidnight — время суток; ужин до 04:00 считаем продолжением
+// предыдущего вечера, чтобы 00:30 не тянуло среднее к утру.
+func SinceMidnight(t time.Time) time.Duration {
+	d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if t.Hour() < 4 {
+		d += 24 * time.Hour
+	}
+	return d
+}
+
+var noComplaintsWords = map[string]bool{
+	"нет":       true,
+	"нет жалоб": true,
+	"без жалоб": true,
+	"-":         true,
+
+	"no":            true,
+	"none":          true,
+	"no complaints": true,
+}
+
+// IsNoComplaints — ответ на утренний вопрос означает «жалоб нет».
+func IsNoComplaints(text string) bool {
+	t := strings.ToLower(strings.TrimSpace(text))
+	t = strings.TrimRight(t, ".!")
+	return noComplaintsWords[t]
+}
+
+// Fine — утро без жалоб: «Нет жалоб» или оценка без симптомов в чек-ине,
+// либо текстовый ответ вроде «нет». Утро без ответа — не Fine.
+func Fine(rec models.DayRecord) bool {
+	if !rec.MorningAnswered() {
+		return false
+	}
+	if c := rec.CheckIn; c != nil && len(c.Symptoms) > 0 {
+		return false
+	}
+	return rec.Complaints == "" || IsNoComplaints(rec.Complaints)
+}
+
+// Morning — утренний ответ одной строкой: «нет жалоб», оценка, симптомы
+// (названия — из списка пользователя catalog) и текст; "" — ответа нет.
+func Morning(l i18n.Lang, rec models.DayRecord, catalog []models.Symptom) string {
+	var parts []string
+	if c := rec.CheckIn; c != nil {
+		if c.NoComplaints {
+			parts = append(parts, l.T("checkin.none"))
+		}
+		if c.Score != nil {
+			parts = append(parts, l.T("checkin.score", *c.Score, models.MaxScore))
+		}
+		if len(c.Symptoms) > 0 {
+			parts = append(parts, SymptomNames(l, catalog, c.Symptoms))
+		}
+	}
+	if rec.Complaints != "" {
+		parts = append(parts, rec.Complaints)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// SymptomNames — названия симптомов keys через запятую в порядке списка
+// пользователя catalog. Ключа нет в catalog — это встроенный симптом.
+func SymptomNames(l i18n.Lang, catalog []models.Symptom, keys []string) string {
+	var names []string
+	for _, s := range catalog {
+		if slices.Contains(keys, s.Key) {
+			names = append(names, SymptomName(l, s))
+		}
+	}
+	for _, k := range keys {
+		if !slices.ContainsFunc(catalog, func(s models.Symptom) bool { return s.Key == k }) {
+			names = append(names, SymptomName(l, models.Symptom{Key: k}))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// SymptomName — своё название симптома или встроенное из перевода.
+func SymptomName(l i18n.Lang, s models.Symptom) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return l.T("symptom." + s.Key)
+}
+
+// Format рендерит отчёт для отправки в чат.
+func Format(l i18n.Lang, r Report) string {
+	var b strings.Builder
+
+	b.WriteString(l.T("stats.title", int(r.Period), r.From, r.To) + "\n\n")
+
+	if r.Days == 0 {
+		b.WriteString(l.T("stats.empty"))
+		return b.String()
+	}
+
+	b.WriteString(l.T("stats.with", r.WithComplaints) + "\n")
+	b.WriteString(l.T("stats.without", r.NoComplaints) + "\n")
+	if r.Scored > 0 {
+		b.WriteString(l.T("stats.score_avg", r.ScoreAvg, models.MaxScore) + "\n")
+	}
+	b.WriteString("\n")
+
+	if r.EveningAnswered > 0 {
+		b.WriteString(l.T("stats.dinner_avg", Clock(r.DinnerAvg)) + "\n")
+		b.WriteString(l.T("stats.dinner_median", Clock(r.DinnerMedian)) + "\n")
+		b.WriteString(l.T("stats.dinner_last", r.LastDinner.Format("02.01 15:04")) + "\n\n")
+	} else {
+		b.WriteString(l.T("stats.no_dinners") + "\n\n")
+	}
+
+	b.WriteString(l.T("stats.morning", r.MorningAnswered, r.Days, percent(r.MorningAnswered, r.Days)) + "\n")
+	b.WriteString(l.T("stats.evening", r.EveningAnswered, r.Days, percent(r.EveningAnswered, r.Days)) + "\n\n")
+
+	b.WriteString(l.T("stats.streak", r.AnswerStreak) + "\n")
+	b.WriteString(l.T("stats.streak_ok", r.NoComplaintsStreak))
+	return b.String()
+}
+
+// Clock: время от полуночи → "HH:MM" (24:30 → "00:30").
+func Clock(d time.Duration) string {
+	d = d.Round(time.Minute) % (24 * time.Hour)
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
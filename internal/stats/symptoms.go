@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+)
+
+// SymptomCount — сколько утр отмечен симптом.
+type SymptomCount struct {
+	Symptom models.Symptom
+	Days    int
+}
+
+// Frequency — частота симптомов за период.
+type Frequency struct {
+	Period   Period
+	From, To string
+	CheckIns int            // утр с чек-ином — база для процентов
+	Counts   []SymptomCount // чаще — выше; архивные — только если встречались
+}
+
+// BuildFrequency считает симптомы из списка catalog по чек-инам recs за
+// период [from, to]. Утро без чек-ина (только текст) не учитывается.
+func BuildFrequency(recs []models.DayRecord, catalog []models.Symptom, p Period, from, to string) Frequency {
+	f := Frequency{Period: p, From: from, To: to}
+	days := map[string]int{}
+	for _, rec := range recs {
+		if rec.CheckIn == nil || rec.Day < from || rec.Day > to {
+			continue
+		}
+		f.CheckIns++
+		for _, k := range rec.CheckIn.Symptoms {
+			days[k]++
+		}
+	}
+	for _, s := range catalog {
+		if s.Archived && days[s.Key] == 0 {
+			continue
+		}
+		f.Counts = append(f.Counts, SymptomCount{Symptom: s, Days: days[s.Key]})
+	}
+	sort.SliceStable(f.Counts, func(i, j int) bool { return f.Counts[i].Days > f.Counts[j].Days })
+	return f
+}
+
+// FormatFrequency рендерит частоту симптомов для чата.
+func FormatFrequency(l i18n.Lang, f Frequency) string {
+	var b strings.Builder
+	b.WriteString(l.T("symfreq.title", int(f.Period), f.From, f.To) + "\n\n")
+	if f.CheckIns == 0 {
+		b.WriteString(l.T("symfreq.empty"))
+		return b.String()
+	}
+	b.WriteString(l.T("symfreq.base", f.CheckIns) + "\n\n")
+	for _, c := range f.Counts {
+		b.WriteString(l.T("symfreq.row", SymptomName(l, c.Symptom), c.Days, percent(c.Days, f.CheckIns)) + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,166 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"telegram-health-dairy/internal/i18n"
+	"telegram-health-dairy/internal/models"
+)
+
+// TriggerLift — во столько раз чаще среднего жалобы после тега, чтобы
+// считать его вероятным триггером.
+const TriggerLift = 1.5
+
+// TagRate — утра после дней, когда ели что-то с тегом Tag.
+type TagRate struct {
+	Tag        string
+	Total      int // пар «день с тегом → ответ следующим утром»
+	Complaints int // из них утро с жалобами
+	Lift       float64
+}
+
+func (t TagRate) Rate() float64 {
+	if t.Total == 0 {
+		return 0
+	}
+	return float64(t.Complaints) / float64(t.Total)
+}
+
+// Triggers связывает теги еды дня N с жалобами утром дня N+1.
+type Triggers struct {
+	Base Bucket    // все дни с записанной едой, Hour не используется
+	Tags []TagRate // не меньше MinGroupSize пар, по убыванию Lift
+	Few  []TagRate // пар меньше MinGroupSize, по убыванию Total
+}
+
+// BuildTriggers собирает теги дня из ужина и дневника питания. Как и в
+// Correlate, пары без ответа утром не учитываются.
+func BuildTriggers(recs []models.DayRecord, meals []models.Meal, loc *time.Location) Triggers {
+	byDay := make(map[string]models.DayRecord, len(recs))
+	tags := map[string][]string{}
+	for _, rec := range recs {
+		byDay[rec.Day] = rec
+		if rec.DinnerAt != nil {
+			tags[rec.Day] = addTags(tags[rec.Day], rec.DinnerTags)
+		}
+	}
+	for _, m := range meals {
+		tags[m.Day] = addTags(tags[m.Day], m.Tags)
+	}
+
+	var t Triggers
+	rates := map[string]*TagRate{}
+	for day, dayTags := range tags {
+		d, err := time.ParseInLocation(dayLayout, day, loc)
+		if err != nil {
+			continue
+		}
+		next, ok := byDay[d.AddDate(0, 0, 1).Format(dayLayout)]
+		if !ok || !next.MorningAnswered() {
+			continue
+		}
+		bad := !Fine(next)
+
+		t.Base.Total++
+		if bad {
+			t.Base.Complaints++
+		}
+		for _, tag := range dayTags {
+			r := rates[tag]
+			if r == nil {
+				r = &TagRate{Tag: tag}
+				rates[tag] = r
+			}
+			r.Total++
+			if bad {
+				r.Complaints++
+			}
+		}
+	}
+
+	for _, r := range rates {
+		// без жалоб вообще разницы нет: и среднее, и каждый тег — 0%
+		r.Lift = 1
+		if t.Base.Complaints > 0 {
+			r.Lift = r.Rate() / t.Base.Rate()
+		}
+		if r.Total >= MinGroupSize {
+			t.Tags = append(t.Tags, *r)
+		} else {
+			t.Few = append(t.Few, *r)
+		}
+	}
+	sort.Slice(t.Tags, func(i, j int) bool {
+		a, b := t.Tags[i], t.Tags[j]
+		if a.Lift != b.Lift {
+			return a.Lift > b.Lift
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Tag < b.Tag
+	})
+	sort.Slice(t.Few, func(i, j int) bool {
+		if t.Few[i].Total != t.Few[j].Total {
+			return t.Few[i].Total > t.Few[j].Total
+		}
+		return t.Few[i].Tag < t.Few[j].Tag
+	})
+	return t
+}
+
+// addTags дописывает в set теги, которых там ещё нет
+func addTags(set, tags []string) []string {
+	for _, tag := range tags {
+		if !slices.Contains(set, tag) {
+			set = append(set, tag)
+		}
+	}
+	return set
+}
+
+// TagName — встроенный тег из перевода, свой — как его назвал пользователь.
+func TagName(l i18n.Lang, tag string) string {
+	if slices.Contains(models.MealTags, tag) {
+		return l.T("tag." + tag)
+	}
+	return tag
+}
+
+// FormatTriggers рендерит разбор для отправки в чат.
+func FormatTriggers(l i18n.Lang, t Triggers) string {
+	var b strings.Builder
+
+	b.WriteString(l.T("trig.title") + "\n\n")
+	if len(t.Tags) == 0 && len(t.Few) == 0 {
+		b.WriteString(l.T("trig.empty"))
+		return b.String()
+	}
+
+	base := percent(t.Base.Complaints, t.Base.Total)
+	b.WriteString(l.T("trig.base", base, t.Base.Complaints, t.Base.Total) + "\n")
+	if len(t.Tags) > 0 {
+		b.WriteString("\n")
+	}
+	for _, r := range t.Tags {
+		mark := "▫️"
+		if r.Lift >= TriggerLift {
+			mark = "⚠️"
+		}
+		b.WriteString(mark + " " + l.T("trig.row", TagName(l, r.Tag),
+			percent(r.Complaints, r.Total), base, r.Complaints, r.Total, r.Lift) + "\n")
+	}
+
+	if len(t.Few) > 0 {
+		var few []string
+		for _, r := range t.Few {
+			few = append(few, fmt.Sprintf("%s (%d)", TagName(l, r.Tag), r.Total))
+		}
+		b.WriteString("\n" + l.T("trig.few", MinGroupSize, strings.Join(few, ", ")))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
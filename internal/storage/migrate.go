@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Миграции лежат в migrations/<driver>/NNNN_описание.sql и применяются строго по
+// возрастанию номера. Уже выпущенные файлы не редактируем — только добавляем новые,
+// причём с одним и тем же номером для каждого драйвера.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationsFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+func loadMigrations(driver string) ([]migration, error) {
+	dir := "migrations/" + driver
+	entries, err := migrationsFS.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []migration
+	seen := map[int]string{}
+	for _, e := range entries {
+		name := e.Name()
+		num, _, ok := strings.Cut(name, "_")
+		if !ok || path.Ext(name) != ".sql" {
+			return nil, fmt.Errorf("migration %q: ожидается имя вида NNNN_name.sql", name)
+		}
+		v, err := strconv.Atoi(num)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("migration %q: неверный номер версии", name)
+		}
+		if prev, dup := seen[v]; dup {
+			return nil, fmt.Errorf("migrations %q и %q: одинаковая версия %d", prev, name, v)
+		}
+		seen[v] = name
+
+		b, err := migrationsFS.ReadFile(dir + "/" + name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, migration{version: v, name: name, sql: string(b)})
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].version < res[j].version })
+	return res, nil
+}
+
+// migrate доводит схему до последней версии, каждую миграцию — в своей транзакции.
+// Если база новее бинарника (откатили релиз), отказываемся стартовать.
+func (d *DB) migrate() error {
+	ms, err := loadMigrations(d.driver)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations(
+          version    INTEGER PRIMARY KEY,
+          applied_at BIGINT  NOT NULL
+        )`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := d.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	latest := 0
+	if len(ms) > 0 {
+		latest = ms[len(ms)-1].version
+	}
+	if current > latest {
+		return fmt.Errorf("версия схемы БД %d новее, чем знает бинарник (%d): обновите бот", current, latest)
+	}
+
+	for _, m := range ms {
+		if m.version <= current {
+			continue
+		}
+		if err := d.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+func (d *DB) applyMigration(m migration) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		d.rebind(`INSERT INTO schema_migrations(version, applied_at) VALUES (?, ?)`),
+		m.version, time.Now().Unix(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
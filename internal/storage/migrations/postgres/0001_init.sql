@@ -0,0 +1,40 @@
+-- Исходная схема, PostgreSQL-вариант migrations/sqlite/0001_init.sql.
+
+CREATE TABLE IF NOT EXISTS users(
+  id          BIGSERIAL PRIMARY KEY,
+  chat_id     BIGINT  UNIQUE,
+  tz          TEXT    NOT NULL DEFAULT 'Europe/Moscow',
+  morning_at  TEXT    NOT NULL DEFAULT '10:00',
+  evening_at  TEXT    NOT NULL DEFAULT '18:00',
+  created_at  BIGINT  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS day_records(
+  id          BIGSERIAL PRIMARY KEY,
+  chat_id     BIGINT  NOT NULL REFERENCES users(chat_id) ON DELETE CASCADE,
+  day         TEXT    NOT NULL,
+  complaints  TEXT,
+  dinner_at   BIGINT,
+  UNIQUE(chat_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS pending_messages(
+  id          BIGSERIAL PRIMARY KEY,
+  chat_id     BIGINT  NOT NULL,
+  date_key    TEXT    NOT NULL,
+  type        TEXT    NOT NULL,
+  msg_id      BIGINT  NOT NULL,
+  created_at  BIGINT  NOT NULL,
+  reminded_at BIGINT  NOT NULL DEFAULT 0,
+  UNIQUE(chat_id, date_key)
+);
+
+CREATE TABLE IF NOT EXISTS user_states(
+  chat_id BIGINT PRIMARY KEY,
+  state   TEXT
+);
+
+CREATE TABLE IF NOT EXISTS sessions(
+  chat_id BIGINT PRIMARY KEY,
+  state   TEXT NOT NULL
+);
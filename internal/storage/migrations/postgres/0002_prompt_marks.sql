@@ -0,0 +1,10 @@
+-- Отметки о вопросах, которые так и не были заданы (бот лежал — missed)
+-- или которые пользователь сознательно пропустил (skipped).
+
+CREATE TABLE prompt_marks(
+  chat_id     BIGINT  NOT NULL,
+  date_key    TEXT    NOT NULL,
+  status      TEXT    NOT NULL,
+  created_at  BIGINT  NOT NULL,
+  UNIQUE(chat_id, date_key)
+);
@@ -0,0 +1,10 @@
+-- Политика напоминаний о вопросах без ответа: как часто и сколько раз
+-- напоминать и в какие часы молчать. Пустые quiet_* — тихих часов нет.
+-- reminders — сколько напоминаний по вопросу уже отправлено.
+
+ALTER TABLE users ADD COLUMN remind_every INTEGER NOT NULL DEFAULT 20;
+ALTER TABLE users ADD COLUMN remind_max INTEGER NOT NULL DEFAULT 3;
+ALTER TABLE users ADD COLUMN quiet_from TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN quiet_to TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE pending_messages ADD COLUMN reminders INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Утренний чек-ин к записи дня: «нет жалоб», самочувствие 0–10 и
+-- отмеченные симптомы. Свободный текст остаётся в day_records.complaints.
+-- Строки удаляются вместе с записью дня (ON DELETE CASCADE).
+
+CREATE TABLE checkins(
+  day_record_id BIGINT PRIMARY KEY REFERENCES day_records(id) ON DELETE CASCADE,
+  no_complaints BOOLEAN NOT NULL DEFAULT FALSE,
+  score         INTEGER,
+  answered_at   BIGINT NOT NULL
+);
+
+CREATE TABLE checkin_symptoms(
+  day_record_id BIGINT NOT NULL REFERENCES checkins(day_record_id) ON DELETE CASCADE,
+  symptom       TEXT    NOT NULL,
+  UNIQUE(day_record_id, symptom)
+);
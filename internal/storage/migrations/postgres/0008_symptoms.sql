@@ -0,0 +1,14 @@
+-- Свой список симптомов у каждого пользователя. key попадает в
+-- checkin_symptoms.symptom; у встроенных симптомов это ключ из
+-- models.DefaultSymptoms, пустое name — название из перевода.
+-- Симптомы не удаляются, а уходят в архив, чтобы история не теряла названий.
+
+CREATE TABLE symptoms(
+  id          BIGSERIAL PRIMARY KEY,
+  chat_id     BIGINT NOT NULL,
+  key         TEXT    NOT NULL,
+  name        TEXT    NOT NULL DEFAULT '',
+  position    INTEGER NOT NULL DEFAULT 0,
+  archived    BOOLEAN NOT NULL DEFAULT FALSE,
+  UNIQUE(chat_id, key)
+);
@@ -0,0 +1,22 @@
+-- Дневник питания: сколько угодно приёмов пищи в день со временем,
+-- описанием, необязательным фото (file_id в Telegram) и тегами.
+-- day — дата по времени пользователя, eaten_at — момент приёма пищи.
+
+CREATE TABLE meals(
+  id          BIGSERIAL PRIMARY KEY,
+  chat_id     BIGINT NOT NULL,
+  day         TEXT    NOT NULL,
+  eaten_at    BIGINT NOT NULL,
+  kind        TEXT    NOT NULL,
+  description TEXT    NOT NULL DEFAULT '',
+  photo_id    TEXT    NOT NULL DEFAULT '',
+  created_at  BIGINT NOT NULL
+);
+
+CREATE INDEX meals_chat_day ON meals(chat_id, day);
+
+CREATE TABLE meal_tags(
+  meal_id     BIGINT NOT NULL REFERENCES meals(id) ON DELETE CASCADE,
+  tag         TEXT    NOT NULL,
+  UNIQUE(meal_id, tag)
+);
@@ -1,4 +1,6 @@
-PRAGMA foreign_keys = ON;
+-- Исходная схема. IF NOT EXISTS оставлен, чтобы базы, созданные
+-- до появления schema_migrations, приняли эту миграцию без ошибок.
+
 
 CREATE TABLE IF NOT EXISTS users(
   id          INTEGER PRIMARY KEY AUTOINCREMENT,
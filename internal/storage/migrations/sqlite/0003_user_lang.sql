@@ -0,0 +1,4 @@
+-- Язык интерфейса пользователя. Все, кто был до этой миграции,
+-- пользовались русским.
+
+ALTER TABLE users ADD COLUMN lang TEXT NOT NULL DEFAULT 'ru';
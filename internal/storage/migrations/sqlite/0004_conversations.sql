@@ -0,0 +1,4 @@
+-- Состояние диалога теперь хранится JSON (см. fsm). Старые строковые
+-- состояния — это незаконченные вопросы, переносить их незачем.
+
+DELETE FROM user_states;
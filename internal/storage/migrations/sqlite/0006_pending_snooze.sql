@@ -0,0 +1,4 @@
+-- «Напомнить через …»: до snooze_until (unix) напоминаний о вопросе нет,
+-- а после — одно напоминание вне политики пользователя. 0 — не откладывали.
+
+ALTER TABLE pending_messages ADD COLUMN snooze_until INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Утренний чек-ин к записи дня: «нет жалоб», самочувствие 0–10 и
+-- отмеченные симптомы. Свободный текст остаётся в day_records.complaints.
+-- Строки удаляются вместе с записью дня (ON DELETE CASCADE).
+
+CREATE TABLE checkins(
+  day_record_id INTEGER PRIMARY KEY REFERENCES day_records(id) ON DELETE CASCADE,
+  no_complaints INTEGER NOT NULL DEFAULT 0,
+  score         INTEGER,
+  answered_at   INTEGER NOT NULL
+);
+
+CREATE TABLE checkin_symptoms(
+  day_record_id INTEGER NOT NULL REFERENCES checkins(day_record_id) ON DELETE CASCADE,
+  symptom       TEXT    NOT NULL,
+  UNIQUE(day_record_id, symptom)
+);
@@ -0,0 +1,8 @@
+-- Что было на ужин: ключи models.MealTags или свои названия пользователя.
+-- Строки удаляются вместе с записью дня (ON DELETE CASCADE).
+
+CREATE TABLE dinner_tags(
+  day_record_id INTEGER NOT NULL REFERENCES day_records(id) ON DELETE CASCADE,
+  tag           TEXT    NOT NULL,
+  UNIQUE(day_record_id, tag)
+);
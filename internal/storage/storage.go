@@ -2,27 +2,85 @@ package storage
 
 import (
 	"database/sql"
-	"embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	_ "github.com/jackc/pgx/v5/stdlib"
 	_ "modernc.org/sqlite"
 
-	"telegram-health-dairy/internal/config"
+	"telegram-health-dairy/internal/clock"
 	"telegram-health-dairy/internal/models"
 )
 
-//go:embed schema.sql
-var ddl embed.FS
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// DB — реализация Store поверх database/sql. Запросы пишутся с плейсхолдерами
+// "?" и диалектом SQLite/PostgreSQL-совместимого подмножества; для Postgres
+// плейсхолдеры переписываются в $1, $2, … (см. rebind).
+type DB struct {
+	*sql.DB
+	driver string
+	dsn    string
+	clock  clock.Clock // created_at новых строк
+}
+
+var _ Store = (*DB)(nil)
+
+// Open подключается к базе выбранного драйвера и применяет миграции.
+func Open(driver, dsn string) (*DB, error) {
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch driver {
+	case DriverSQLite:
+		db, err = sql.Open("sqlite", dsn+"?_pragma=foreign_keys(1)")
+	case DriverPostgres:
+		db, err = sql.Open("pgx", dsn)
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер БД %q", driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DB{DB: db, driver: driver, dsn: dsn, clock: clock.Real()}
+	if err = d.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// SetClock подменяет часы, которыми помечается время создания записей.
+func (d *DB) SetClock(c clock.Clock) {
+	d.clock = c
+}
 
-type DB struct{ *sql.DB }
+// New открывает SQLite-базу по пути к файлу.
+func New(path string) (*DB, error) {
+	return Open(DriverSQLite, path)
+}
 
+// DropAll удаляет все данные вместе со схемой; после этого бот нужно перезапустить.
 func (d *DB) DropAll() error {
+	if d.driver == DriverSQLite {
+		d.Close()
+		return os.Remove(d.dsn)
+	}
+
+	_, err := d.DB.Exec(`DROP TABLE IF EXISTS
+        checkin_symptoms, checkins, dinner_tags, symptoms, meal_tags, meals, day_records, pending_messages, prompt_marks, user_states, sessions, users,
+        schema_migrations CASCADE`)
 	d.Close()
-	return os.Remove(config.DBName)
+	return err
 }
 
 // ClearData полностью очищает все данные по пользователю
@@ -33,16 +91,21 @@ func (d *DB) ClearData(chatID int64) error {
 	}
 	defer tx.Rollback()
 
+	// чек-ины и теги ужина удаляются вместе с day_records, теги еды —
+	// вместе с meals (ON DELETE CASCADE)
 	tables := []string{
 		"day_records",
 		"pending_messages",
+		"prompt_marks",
+		"symptoms",
+		"meals",
 		"user_states",
 		"sessions",
 		"users",
 	}
 	for _, tbl := range tables {
 		if _, err := tx.Exec(
-			fmt.Sprintf("DELETE FROM %s WHERE chat_id = ?", tbl),
+			d.rebind(fmt.Sprintf("DELETE FROM %s WHERE chat_id = ?", tbl)),
 			chatID,
 		); err != nil {
 			return err
@@ -52,46 +115,74 @@ func (d *DB) ClearData(chatID int64) error {
 	return tx.Commit()
 }
 
-func New(path string) (*DB, error) {
-	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
-	if err != nil {
-		return nil, err
-	}
-	if err = migrate(db); err != nil {
-		return nil, err
-	}
-	return &DB{db}, nil
+// ---------- dialect ---------------------------------------------------------
+
+// Exec, Query и QueryRow перекрывают методы *sql.DB, чтобы все запросы
+// пакета проходили через rebind.
+func (d *DB) Exec(query string, args ...any) (sql.Result, error) {
+	return d.DB.Exec(d.rebind(query), args...)
 }
 
-func migrate(db *sql.DB) error {
-	b, err := ddl.ReadFile("schema.sql")
-	if err != nil {
-		return err
+func (d *DB) Query(query string, args ...any) (*sql.Rows, error) {
+	return d.DB.Query(d.rebind(query), args...)
+}
+
+func (d *DB) QueryRow(query string, args ...any) *sql.Row {
+	return d.DB.QueryRow(d.rebind(query), args...)
+}
+
+// rebind переписывает "?" в "$N" для PostgreSQL
+func (d *DB) rebind(query string) string {
+	if d.driver != DriverPostgres {
+		return query
 	}
-	_, err = db.Exec(string(b))
-	return err
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
 }
 
 // ---------- users -----------------------------------------------------------
 
+// userColumns — порядок полей для userFields
+const userColumns = `id, chat_id, tz, morning_at, evening_at, lang, created_at,
+        remind_every, remind_max, quiet_from, quiet_to`
+
+func userFields(u *models.User) []any {
+	return []any{&u.ID, &u.ChatID, &u.TZ, &u.MorningAt, &u.EveningAt, &u.Lang, &u.CreatedAt,
+		&u.RemindEvery, &u.RemindMax, &u.QuietFrom, &u.QuietTo}
+}
+
 func (d *DB) UpsertUser(u *models.User) error {
 	_, err := d.Exec(`
-        INSERT INTO users (chat_id, tz, morning_at, evening_at, created_at)
-        VALUES (?,?,?,?,?)
+        INSERT INTO users (chat_id, tz, morning_at, evening_at, lang, created_at,
+            remind_every, remind_max, quiet_from, quiet_to)
+        VALUES (?,?,?,?,?,?,?,?,?,?)
         ON CONFLICT(chat_id) DO UPDATE SET tz=excluded.tz,
             morning_at=excluded.morning_at,
-            evening_at=excluded.evening_at
-    `, u.ChatID, u.TZ, u.MorningAt, u.EveningAt, time.Now().Unix())
+            evening_at=excluded.evening_at,
+            lang=excluded.lang,
+            remind_every=excluded.remind_every,
+            remind_max=excluded.remind_max,
+            quiet_from=excluded.quiet_from,
+            quiet_to=excluded.quiet_to
+    `, u.ChatID, u.TZ, u.MorningAt, u.EveningAt, u.Lang, d.clock.Now().Unix(),
+		u.RemindEvery, u.RemindMax, u.QuietFrom, u.QuietTo)
 	return err
 }
 
 func (d *DB) GetUser(chatID int64) (*models.User, error) {
 	var u models.User
 
-	err := d.QueryRow(`
-        SELECT id, chat_id, tz, morning_at, evening_at, created_at
-        FROM users WHERE chat_id=?`, chatID,
-	).Scan(&u.ID, &u.ChatID, &u.TZ, &u.MorningAt, &u.EveningAt, &u.CreatedAt)
+	err := d.QueryRow(`SELECT `+userColumns+` FROM users WHERE chat_id=?`, chatID).
+		Scan(userFields(&u)...)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -101,7 +192,7 @@ func (d *DB) GetUser(chatID int64) (*models.User, error) {
 }
 
 func (d *DB) ListUsers() ([]models.User, error) {
-	rows, err := d.Query(`SELECT id, chat_id, tz, morning_at, evening_at, created_at FROM users`)
+	rows, err := d.Query(`SELECT ` + userColumns + ` FROM users`)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +200,7 @@ func (d *DB) ListUsers() ([]models.User, error) {
 	var res []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.ChatID, &u.TZ, &u.MorningAt, &u.EveningAt, &u.CreatedAt); err != nil {
+		if err := rows.Scan(userFields(&u)...); err != nil {
 			return nil, err
 		}
 		res = append(res, u)
@@ -117,22 +208,37 @@ func (d *DB) ListUsers() ([]models.User, error) {
 	return res, nil
 }
 
-// ---------- user state (fsm) ------------------------------------------------
+// ---------- conversations (fsm) ---------------------------------------------
 
-func (d *DB) SetUserState(chatID int64, state string) error {
-	_, err := d.Exec(`
+func (d *DB) SetConversation(chatID int64, c *models.Conversation) error {
+	if c == nil {
+		_, err := d.Exec(`DELETE FROM user_states WHERE chat_id=?`, chatID)
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = d.Exec(`
         INSERT INTO user_states(chat_id, state) VALUES (?,?)
-        ON CONFLICT(chat_id) DO UPDATE SET state=excluded.state`, chatID, state)
+        ON CONFLICT(chat_id) DO UPDATE SET state=excluded.state`, chatID, string(data))
 	return err
 }
 
-func (d *DB) GetUserState(chatID int64) (string, error) {
-	var st string
+func (d *DB) GetConversation(chatID int64) (*models.Conversation, error) {
+	var st sql.NullString
 	err := d.QueryRow(`SELECT state FROM user_states WHERE chat_id=?`, chatID).Scan(&st)
-	if errors.Is(err, sql.ErrNoRows) {
-		return "", nil
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && st.String == "") {
+		return nil, nil
 	}
-	return st, err
+	if err != nil {
+		return nil, err
+	}
+	var c models.Conversation
+	if err := json.Unmarshal([]byte(st.String), &c); err != nil {
+		return nil, fmt.Errorf("user_states %d: %w", chatID, err)
+	}
+	return &c, nil
 }
 
 // ---------- day records -----------------------------------------------------
@@ -153,55 +259,573 @@ func (d *DB) SetDinner(chatID int64, day string, t time.Time) error {
 	return err
 }
 
+// ImportDayRecords записывает дни одной транзакцией. Пустые поля записи
+// (complaints "", DinnerAt nil, CheckIn nil) сохранённые не трогают.
+// replace — заданные поля перезаписывают сохранённые, чек-ин заменяется
+// целиком; иначе заполняются только пустые поля, а чек-ин дополняется
+// (см. fillCheckIn).
+func (d *DB) ImportDayRecords(chatID int64, recs []models.DayRecord, replace bool) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert := `
+        INSERT INTO day_records(chat_id, day, complaints, dinner_at) VALUES (?,?,?,?)
+        ON CONFLICT(chat_id,day) DO UPDATE SET
+            complaints=COALESCE(NULLIF(day_records.complaints, ''), excluded.complaints),
+            dinner_at=COALESCE(day_records.dinner_at, excluded.dinner_at)`
+	saveCheckIn := d.fillCheckIn
+	if replace {
+		upsert = `
+        INSERT INTO day_records(chat_id, day, complaints, dinner_at) VALUES (?,?,?,?)
+        ON CONFLICT(chat_id,day) DO UPDATE SET
+            complaints=COALESCE(excluded.complaints, day_records.complaints),
+            dinner_at=COALESCE(excluded.dinner_at, day_records.dinner_at)`
+		saveCheckIn = d.putCheckIn
+	}
+	stmt, err := tx.Prepare(d.rebind(upsert))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rec := range recs {
+		var complaints sql.NullString
+		if rec.Complaints != "" {
+			complaints = sql.NullString{String: rec.Complaints, Valid: true}
+		}
+		var dinner sql.NullInt64
+		if rec.DinnerAt != nil {
+			dinner = sql.NullInt64{Int64: rec.DinnerAt.Unix(), Valid: true}
+		}
+		if _, err := stmt.Exec(chatID, rec.Day, complaints, dinner); err != nil {
+			return fmt.Errorf("%s: %w", rec.Day, err)
+		}
+		if rec.CheckIn != nil {
+			if err := saveCheckIn(tx, chatID, rec.Day, rec.CheckIn); err != nil {
+				return fmt.Errorf("%s: %w", rec.Day, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
 func (d *DB) GetDayRecord(chatID int64, day string) (*models.DayRecord, error) {
-	var rec models.DayRecord
-	var dinnerTs sql.NullInt64
-	err := d.QueryRow(`
-        SELECT id, chat_id, day, complaints, dinner_at
-        FROM day_records WHERE chat_id=? AND day=?`, chatID, day,
-	).Scan(&rec.ID, &rec.ChatID, &rec.Day, &rec.Complaints, &dinnerTs)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, nil
+	recs, err := d.ListDayRecords(chatID, day, day)
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	return &recs[0], nil
+}
+
+// ListDayRecords возвращает записи за период [from, to] (YYYY-MM-DD) по
+// возрастанию дня вместе с чек-инами
+func (d *DB) ListDayRecords(chatID int64, from, to string) ([]models.DayRecord, error) {
+	rows, err := d.Query(`
+        SELECT r.id, r.chat_id, r.day, r.complaints, r.dinner_at,
+               c.day_record_id, c.no_complaints, c.score
+        FROM day_records AS r
+        LEFT JOIN checkins AS c ON c.day_record_id = r.id
+        WHERE r.chat_id=? AND r.day BETWEEN ? AND ?
+        ORDER BY r.day`, chatID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.DayRecord
+	for rows.Next() {
+		rec, err := scanDayRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := d.loadSymptoms(chatID, from, to, res); err != nil {
+		return nil, err
+	}
+	return res, d.loadDinnerTags(chatID, from, to, res)
+}
+
+// FirstDay — самый ранний день с записью (YYYY-MM-DD), "" — записей нет.
+// Импорт может принести дни раньше регистрации.
+func (d *DB) FirstDay(chatID int64) (string, error) {
+	var day sql.NullString
+	err := d.QueryRow(`SELECT MIN(day) FROM day_records WHERE chat_id=?`, chatID).Scan(&day)
+	return day.String, err
+}
+
+// loadSymptoms раскладывает отмеченные симптомы по чек-инам записей recs
+func (d *DB) loadSymptoms(chatID int64, from, to string, recs []models.DayRecord) error {
+	byID := map[int64]*models.CheckIn{}
+	for i := range recs {
+		if recs[i].CheckIn != nil {
+			byID[recs[i].ID] = recs[i].CheckIn
+		}
+	}
+	if len(byID) == 0 {
+		return nil
+	}
+	rows, err := d.Query(`
+        SELECT s.day_record_id, s.symptom
+        FROM checkin_symptoms AS s
+        JOIN day_records AS r ON r.id = s.day_record_id
+        WHERE r.chat_id=? AND r.day BETWEEN ? AND ?
+        ORDER BY s.day_record_id, s.symptom`, chatID, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var sym string
+		if err := rows.Scan(&id, &sym); err != nil {
+			return err
+		}
+		if c := byID[id]; c != nil {
+			c.Symptoms = append(c.Symptoms, sym)
+		}
+	}
+	return rows.Err()
+}
+
+// loadDinnerTags раскладывает теги ужина по записям recs
+func (d *DB) loadDinnerTags(chatID int64, from, to string, recs []models.DayRecord) error {
+	byID := map[int64]*models.DayRecord{}
+	for i := range recs {
+		if recs[i].DinnerAt != nil {
+			byID[recs[i].ID] = &recs[i]
+		}
+	}
+	if len(byID) == 0 {
+		return nil
 	}
+	rows, err := d.Query(`
+        SELECT t.day_record_id, t.tag
+        FROM dinner_tags AS t
+        JOIN day_records AS r ON r.id = t.day_record_id
+        WHERE r.chat_id=? AND r.day BETWEEN ? AND ?
+        ORDER BY t.day_record_id, t.tag`, chatID, from, to)
 	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int64
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return err
+		}
+		if rec := byID[id]; rec != nil {
+			rec.DinnerTags = append(rec.DinnerTags, tag)
+		}
+	}
+	return rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanDayRecord: complaints и dinner_at могут быть NULL
+// (например, запись создана только SetDinner), поля чек-ина — если его нет
+func scanDayRecord(s scanner) (*models.DayRecord, error) {
+	var rec models.DayRecord
+	var complaints sql.NullString
+	var dinnerTs, checkinID, score sql.NullInt64
+	var none sql.NullBool
+	if err := s.Scan(&rec.ID, &rec.ChatID, &rec.Day, &complaints, &dinnerTs,
+		&checkinID, &none, &score); err != nil {
 		return nil, err
 	}
+	rec.Complaints = complaints.String
 	if dinnerTs.Valid {
 		t := time.Unix(dinnerTs.Int64, 0)
 		rec.DinnerAt = &t
 	}
+	if checkinID.Valid {
+		rec.CheckIn = &models.CheckIn{NoComplaints: none.Bool}
+		if score.Valid {
+			v := int(score.Int64)
+			rec.CheckIn.Score = &v
+		}
+	}
 	return &rec, nil
 }
 
+// SetDinnerTags заменяет теги ужина дня; сам ужин должен быть уже записан
+func (d *DB) SetDinnerTags(chatID int64, day string, tags []string) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	if err := tx.QueryRow(d.rebind(`SELECT id FROM day_records WHERE chat_id=? AND day=?`),
+		chatID, day).Scan(&id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(d.rebind(`DELETE FROM dinner_tags WHERE day_record_id=?`), id); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(d.rebind(`
+            INSERT INTO dinner_tags(day_record_id, tag) VALUES (?,?)
+            ON CONFLICT DO NOTHING`), id, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListFoodTags — все теги, которыми пользователь отмечал ужины и еду:
+// сначала те, что были в последний раз позже
+func (d *DB) ListFoodTags(chatID int64) ([]string, error) {
+	rows, err := d.Query(`
+        SELECT tag FROM (
+            SELECT t.tag, r.day FROM dinner_tags AS t
+            JOIN day_records AS r ON r.id = t.day_record_id
+            WHERE r.chat_id=?
+            UNION ALL
+            SELECT t.tag, m.day FROM meal_tags AS t
+            JOIN meals AS m ON m.id = t.meal_id
+            WHERE m.chat_id=?
+        ) AS used
+        GROUP BY tag
+        ORDER BY MAX(day) DESC, tag`, chatID, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		res = append(res, tag)
+	}
+	return res, rows.Err()
+}
+
+// ---------- check-ins -------------------------------------------------------
+
+// SaveCheckIn записывает утренний ответ дня: чек-ин и свободный текст note
+// ("" — текста нет). Прежний ответ за это утро заменяется целиком.
+func (d *DB) SaveCheckIn(chatID int64, day string, c *models.CheckIn, note string) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var complaints sql.NullString
+	if note != "" {
+		complaints = sql.NullString{String: note, Valid: true}
+	}
+	if _, err := tx.Exec(d.rebind(`
+        INSERT INTO day_records(chat_id, day, complaints) VALUES (?,?,?)
+        ON CONFLICT(chat_id,day) DO UPDATE SET complaints=excluded.complaints
+    `), chatID, day, complaints); err != nil {
+		return err
+	}
+	if err := d.putCheckIn(tx, chatID, day, c); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// putCheckIn заменяет чек-ин уже существующей записи дня
+func (d *DB) putCheckIn(tx *sql.Tx, chatID int64, day string, c *models.CheckIn) error {
+	var id int64
+	if err := tx.QueryRow(d.rebind(`SELECT id FROM day_records WHERE chat_id=? AND day=?`),
+		chatID, day).Scan(&id); err != nil {
+		return err
+	}
+	var score sql.NullInt64
+	if c.Score != nil {
+		score = sql.NullInt64{Int64: int64(*c.Score), Valid: true}
+	}
+	if _, err := tx.Exec(d.rebind(`
+        INSERT INTO checkins(day_record_id, no_complaints, score, answered_at) VALUES (?,?,?,?)
+        ON CONFLICT(day_record_id) DO UPDATE SET no_complaints=excluded.no_complaints,
+            score=excluded.score, answered_at=excluded.answered_at
+    `), id, c.NoComplaints, score, d.clock.Now().Unix()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(d.rebind(`DELETE FROM checkin_symptoms WHERE day_record_id=?`), id); err != nil {
+		return err
+	}
+	for _, sym := range c.Symptoms {
+		if _, err := tx.Exec(d.rebind(`
+            INSERT INTO checkin_symptoms(day_record_id, symptom) VALUES (?,?)
+            ON CONFLICT DO NOTHING`), id, sym); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillCheckIn дополняет сохранённый чек-ин: оценка — если её не было,
+// симптомы — если не было ни одного (тогда снимается и «без жалоб»).
+// Чек-ина нет — записывается c.
+func (d *DB) fillCheckIn(tx *sql.Tx, chatID int64, day string, c *models.CheckIn) error {
+	var id int64
+	if err := tx.QueryRow(d.rebind(`SELECT id FROM day_records WHERE chat_id=? AND day=?`),
+		chatID, day).Scan(&id); err != nil {
+		return err
+	}
+	var score sql.NullInt64
+	if c.Score != nil {
+		score = sql.NullInt64{Int64: int64(*c.Score), Valid: true}
+	}
+	if _, err := tx.Exec(d.rebind(`
+        INSERT INTO checkins(day_record_id, no_complaints, score, answered_at) VALUES (?,?,?,?)
+        ON CONFLICT(day_record_id) DO UPDATE SET score=COALESCE(checkins.score, excluded.score)
+    `), id, c.NoComplaints, score, d.clock.Now().Unix()); err != nil {
+		return err
+	}
+	if len(c.Symptoms) == 0 {
+		return nil
+	}
+
+	var n int
+	if err := tx.QueryRow(d.rebind(`SELECT COUNT(*) FROM checkin_symptoms WHERE day_record_id=?`),
+		id).Scan(&n); err != nil || n > 0 {
+		return err
+	}
+	if _, err := tx.Exec(d.rebind(`UPDATE checkins SET no_complaints=? WHERE day_record_id=?`),
+		false, id); err != nil {
+		return err
+	}
+	for _, sym := range c.Symptoms {
+		if _, err := tx.Exec(d.rebind(`
+            INSERT INTO checkin_symptoms(day_record_id, symptom) VALUES (?,?)
+            ON CONFLICT DO NOTHING`), id, sym); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ---------- symptoms --------------------------------------------------------
+
+// ListSymptoms возвращает список симптомов пользователя: сначала активные,
+// потом архив, каждые по порядку. Пустой список при первом обращении
+// заполняется models.DefaultSymptoms.
+func (d *DB) ListSymptoms(chatID int64) ([]models.Symptom, error) {
+	list, err := d.listSymptoms(chatID)
+	if err != nil || len(list) > 0 {
+		return list, err
+	}
+
+	tx, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for i, key := range models.DefaultSymptoms {
+		if _, err := tx.Exec(d.rebind(`
+            INSERT INTO symptoms(chat_id, key, position) VALUES (?,?,?)
+            ON CONFLICT(chat_id, key) DO NOTHING`), chatID, key, i); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return d.listSymptoms(chatID)
+}
+
+func (d *DB) listSymptoms(chatID int64) ([]models.Symptom, error) {
+	rows, err := d.Query(`
+        SELECT key, name, position, archived FROM symptoms
+        WHERE chat_id=?
+        ORDER BY archived, position, id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Symptom
+	for rows.Next() {
+		var s models.Symptom
+		if err := rows.Scan(&s.Key, &s.Name, &s.Position, &s.Archived); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+// AddSymptom добавляет симптом name в конец активного списка. Ключи своих
+// симптомов — c1, c2, …: строки не удаляются, поэтому ключ не повторится.
+func (d *DB) AddSymptom(chatID int64, name string) (*models.Symptom, error) {
+	list, err := d.ListSymptoms(chatID)
+	if err != nil {
+		return nil, err
+	}
+	s := models.Symptom{Key: fmt.Sprintf("c%d", len(list)+1), Name: name}
+	for _, old := range list {
+		s.Position = max(s.Position, old.Position+1)
+	}
+	if _, err := d.Exec(`INSERT INTO symptoms(chat_id, key, name, position) VALUES (?,?,?,?)`,
+		chatID, s.Key, s.Name, s.Position); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (d *DB) RenameSymptom(chatID int64, key, name string) error {
+	_, err := d.Exec(`UPDATE symptoms SET name=? WHERE chat_id=? AND key=?`, name, chatID, key)
+	return err
+}
+
+// ArchiveSymptom убирает симптом в архив (archived) или возвращает из него
+func (d *DB) ArchiveSymptom(chatID int64, key string, archived bool) error {
+	_, err := d.Exec(`UPDATE symptoms SET archived=? WHERE chat_id=? AND key=?`, archived, chatID, key)
+	return err
+}
+
+// ReorderSymptoms расставляет симптомы keys по порядку; остальные не трогает
+func (d *DB) ReorderSymptoms(chatID int64, keys []string) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i, key := range keys {
+		if _, err := tx.Exec(d.rebind(`UPDATE symptoms SET position=? WHERE chat_id=? AND key=?`),
+			i, chatID, key); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ---------- meals -----------------------------------------------------------
+
+// AddMeal записывает приём пищи с тегами и заполняет m.ID
+func (d *DB) AddMeal(m *models.Meal) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRow(d.rebind(`
+        INSERT INTO meals(chat_id, day, eaten_at, kind, description, photo_id, created_at)
+        VALUES (?,?,?,?,?,?,?) RETURNING id`),
+		m.ChatID, m.Day, m.At.Unix(), m.Kind, m.Text, m.PhotoID, d.clock.Now().Unix(),
+	).Scan(&m.ID); err != nil {
+		return err
+	}
+	for _, tag := range m.Tags {
+		if _, err := tx.Exec(d.rebind(`
+            INSERT INTO meal_tags(meal_id, tag) VALUES (?,?)
+            ON CONFLICT DO NOTHING`), m.ID, tag); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListMeals возвращает приёмы пищи за период [from, to] (YYYY-MM-DD) по времени
+func (d *DB) ListMeals(chatID int64, from, to string) ([]models.Meal, error) {
+	rows, err := d.Query(`
+        SELECT id, chat_id, day, eaten_at, kind, description, photo_id
+        FROM meals
+        WHERE chat_id=? AND day BETWEEN ? AND ?
+        ORDER BY eaten_at, id`, chatID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Meal
+	byID := map[int64]int{}
+	for rows.Next() {
+		var m models.Meal
+		var at int64
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Day, &at, &m.Kind, &m.Text, &m.PhotoID); err != nil {
+			return nil, err
+		}
+		m.At = time.Unix(at, 0)
+		byID[m.ID] = len(res)
+		res = append(res, m)
+	}
+	if err := rows.Err(); err != nil || len(res) == 0 {
+		return res, err
+	}
+
+	tags, err := d.Query(`
+        SELECT t.meal_id, t.tag
+        FROM meal_tags AS t
+        JOIN meals AS m ON m.id = t.meal_id
+        WHERE m.chat_id=? AND m.day BETWEEN ? AND ?
+        ORDER BY t.meal_id, t.tag`, chatID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer tags.Close()
+	for tags.Next() {
+		var id int64
+		var tag string
+		if err := tags.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		if i, ok := byID[id]; ok {
+			res[i].Tags = append(res[i].Tags, tag)
+		}
+	}
+	return res, tags.Err()
+}
+
+func (d *DB) DeleteMeal(chatID, id int64) error {
+	_, err := d.Exec(`DELETE FROM meals WHERE chat_id=? AND id=?`, chatID, id)
+	return err
+}
+
 // ---------- pending ---------------------------------------------------------
 
 // InsertPending: теперь инициализируем reminded_at = 0
 func (d *DB) InsertPending(p *models.PendingMessage) error {
-	now := time.Now().Unix()
-
 	if p.CreatedAt == 0 {
-		p.CreatedAt = now
+		p.CreatedAt = d.clock.Now().Unix()
 	}
 	if p.RemindedAt == 0 {
-		p.RemindedAt = now // ← СРАЗУ = время отправки вопроса
+		p.RemindedAt = p.CreatedAt // ← СРАЗУ = время отправки вопроса
 	}
 
 	_, err := d.Exec(`
-        INSERT OR REPLACE INTO pending_messages
+        INSERT INTO pending_messages
           (chat_id, date_key, type, msg_id, created_at, reminded_at)
         VALUES (?,?,?,?,?,?)
+        ON CONFLICT(chat_id, date_key) DO UPDATE SET type=excluded.type,
+            msg_id=excluded.msg_id,
+            created_at=excluded.created_at,
+            reminded_at=excluded.reminded_at,
+            reminders=0,
+            snooze_until=0
     `, p.ChatID, p.DateKey, p.Type, p.MsgID, p.CreatedAt, p.RemindedAt)
 	return err
 }
 
-// ListPendingForReminder возвращает pending’и, которым пора напомнить
-// (прошло ≥ 1200 сек и пользователь ещё не ответил)
-func (d *DB) ListPendingForReminder(chatID int64) ([]models.PendingMessage, error) {
+// ListPending возвращает вопросы пользователя, ждущие ответа. Пора ли
+// напомнить или закрыть вопрос, решает планировщик по политике пользователя.
+func (d *DB) ListPending(chatID int64) ([]models.PendingMessage, error) {
 	rows, err := d.Query(`
-        SELECT id, chat_id, date_key, type, msg_id, created_at, reminded_at
+        SELECT id, chat_id, date_key, type, msg_id, created_at, reminded_at, reminders, snooze_until
         FROM pending_messages
         WHERE chat_id = ?
-          AND reminded_at < strftime('%s','now') - 1200
+        ORDER BY created_at
     `, chatID)
 	if err != nil {
 		return nil, err
@@ -213,20 +837,36 @@ func (d *DB) ListPendingForReminder(chatID int64) ([]models.PendingMessage, erro
 		var p models.PendingMessage
 		if err := rows.Scan(
 			&p.ID, &p.ChatID, &p.DateKey, &p.Type, &p.MsgID,
-			&p.CreatedAt, &p.RemindedAt,
+			&p.CreatedAt, &p.RemindedAt, &p.Reminders, &p.SnoozeUntil,
 		); err != nil {
 			return nil, err
 		}
 		res = append(res, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 	return res, nil
 }
 
-// TouchReminder помечает, что напоминание отправлено (обновляет reminded_at)
-func (d *DB) TouchReminder(id int64) {
+// TouchReminder помечает, что напоминание отправлено в now (обновляет
+// reminded_at и считает напоминания)
+func (d *DB) TouchReminder(id int64, now time.Time) {
 	_, _ = d.Exec(`UPDATE pending_messages
-	               SET reminded_at = strftime('%s','now')
-	               WHERE id = ?`, id)
+	               SET reminded_at = ?, reminders = reminders + 1
+	               WHERE id = ?`, now.Unix(), id)
+}
+
+// SnoozePending откладывает напоминание о вопросе до until; false — такого
+// вопроса уже нет (ответили или он закрыт)
+func (d *DB) SnoozePending(chatID int64, dateKey string, until time.Time) (bool, error) {
+	res, err := d.Exec(`UPDATE pending_messages SET snooze_until = ?
+	                    WHERE chat_id = ? AND date_key = ?`, until.Unix(), chatID, dateKey)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
 func (d *DB) DeletePending(chatID int64, dateKey string) error {
@@ -253,7 +893,7 @@ func (d *DB) HasAnswered(chatID int64, dateKey string) bool {
 		return false
 	}
 	if t == "morning" {
-		return rec.Complaints != ""
+		return rec.MorningAnswered()
 	}
 	if t == "evening" {
 		return rec.DinnerAt != nil
@@ -265,6 +905,50 @@ func (d *DB) HasPendingOrAnswered(chatID int64, dateKey string) bool {
 	return d.HasPending(chatID, dateKey) || d.HasAnswered(chatID, dateKey)
 }
 
+// ---------- prompt marks ----------------------------------------------------
+
+// MarkPrompt отмечает вопрос как пропущенный (status — models.MarkMissed / MarkSkipped)
+func (d *DB) MarkPrompt(chatID int64, dateKey, status string) error {
+	_, err := d.Exec(`
+        INSERT INTO prompt_marks(chat_id, date_key, status, created_at) VALUES (?,?,?,?)
+        ON CONFLICT(chat_id, date_key) DO UPDATE SET status=excluded.status
+    `, chatID, dateKey, status, d.clock.Now().Unix())
+	return err
+}
+
+// GetPromptMark возвращает статус отметки или "" если её нет
+func (d *DB) GetPromptMark(chatID int64, dateKey string) (string, error) {
+	var status string
+	err := d.QueryRow(`SELECT status FROM prompt_marks WHERE chat_id=? AND date_key=?`, chatID, dateKey).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return status, err
+}
+
+// ListPromptMarks — отметки пользователя с данным статусом по возрастанию date_key
+func (d *DB) ListPromptMarks(chatID int64, status string) ([]models.PromptMark, error) {
+	rows, err := d.Query(`
+        SELECT chat_id, date_key, status, created_at
+        FROM prompt_marks
+        WHERE chat_id=? AND status=?
+        ORDER BY date_key`, chatID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.PromptMark
+	for rows.Next() {
+		var m models.PromptMark
+		if err := rows.Scan(&m.ChatID, &m.DateKey, &m.Status, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
 // Session state handling
 func (d *DB) SetSessionState(chatID int64, state models.State) error {
 	_, err := d.Exec(`
@@ -286,3 +970,55 @@ func (d *DB) GetSessionState(chatID int64) (models.State, error) {
 	}
 	return models.State(state), nil
 }
+
+// ListChatsByState возвращает чаты, сессия которых в одном из состояний
+func (d *DB) ListChatsByState(states ...models.State) ([]int64, error) {
+	if len(states) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(states))
+	for i, st := range states {
+		args[i] = string(st)
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?,", len(states)), ",")
+
+	rows, err := d.Query(`SELECT chat_id FROM sessions WHERE state IN (`+marks+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+		res = append(res, chatID)
+	}
+	return res, rows.Err()
+}
+
+// ListIdleUsers — пользователи без активного вопроса (нет сессии тоже считаем idle)
+func (d *DB) ListIdleUsers() ([]models.User, error) {
+	rows, err := d.Query(`
+        SELECT u.id, u.chat_id, u.tz, u.morning_at, u.evening_at, u.lang, u.created_at,
+            u.remind_every, u.remind_max, u.quiet_from, u.quiet_to
+        FROM users AS u
+        LEFT JOIN sessions AS s ON s.chat_id = u.chat_id
+        WHERE COALESCE(s.state, ?) = ?`, string(models.StateIdle), string(models.StateIdle))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(userFields(&u)...); err != nil {
+			return nil, err
+		}
+		res = append(res, u)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,42 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"telegram-health-dairy/internal/storage"
+	"telegram-health-dairy/internal/storage/storetest"
+)
+
+func TestSQLite(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storage.Store {
+		s, err := storage.Open(storage.DriverSQLite, filepath.Join(t.TempDir(), "bot.db"))
+		if err != nil {
+			t.Fatalf("storage.Open: %v", err)
+		}
+		return s
+	})
+}
+
+// TestPostgres запускается, только если задан TEST_DATABASE_URL. База
+// очищается перед каждой проверкой, поэтому рабочую указывать нельзя.
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL не задан")
+	}
+	storetest.Run(t, func(t *testing.T) storage.Store {
+		s, err := storage.Open(storage.DriverPostgres, dsn)
+		if err != nil {
+			t.Fatalf("storage.Open: %v", err)
+		}
+		if err := s.DropAll(); err != nil {
+			t.Fatalf("DropAll: %v", err)
+		}
+		if s, err = storage.Open(storage.DriverPostgres, dsn); err != nil {
+			t.Fatalf("storage.Open: %v", err)
+		}
+		return s
+	})
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"time"
+
+	"telegram-health-dairy/internal/models"
+)
+
+// Store — всё, что обработчикам и планировщику нужно от хранилища.
+// Реализуется *DB поверх SQLite и PostgreSQL.
+type Store interface {
+	// users
+	UpsertUser(u *models.User) error
+	GetUser(chatID int64) (*models.User, error)
+	ListUsers() ([]models.User, error)
+	ListIdleUsers() ([]models.User, error)
+
+	// sessions
+	SetSessionState(chatID int64, state models.State) error
+	GetSessionState(chatID int64) (models.State, error)
+	ListChatsByState(states ...models.State) ([]int64, error)
+
+	// conversations (fsm); nil — диалога нет
+	SetConversation(chatID int64, c *models.Conversation) error
+	GetConversation(chatID int64) (*models.Conversation, error)
+
+	// day records
+	UpsertDayRecord(chatID int64, day, complaints string) error
+	SetDinner(chatID int64, day string, t time.Time) error
+	SetDinnerTags(chatID int64, day string, tags []string) error
+	ListFoodTags(chatID int64) ([]string, error)
+	GetDayRecord(chatID int64, day string) (*models.DayRecord, error)
+	ListDayRecords(chatID int64, from, to string) ([]models.DayRecord, error)
+	// первый день с записью (в том числе импортированной); "" — записей нет
+	FirstDay(chatID int64) (string, error)
+	ImportDayRecords(chatID int64, recs []models.DayRecord, replace bool) error
+	// утренний чек-ин; note — свободный текст ответа
+	SaveCheckIn(chatID int64, day string, c *models.CheckIn, note string) error
+
+	// symptoms: список пользователя, см. models.Symptom
+	ListSymptoms(chatID int64) ([]models.Symptom, error)
+	AddSymptom(chatID int64, name string) (*models.Symptom, error)
+	RenameSymptom(chatID int64, key, name string) error
+	ArchiveSymptom(chatID int64, key string, archived bool) error
+	ReorderSymptoms(chatID int64, keys []string) error
+
+	// meals: дневник питания
+	AddMeal(m *models.Meal) error
+	ListMeals(chatID int64, from, to string) ([]models.Meal, error)
+	DeleteMeal(chatID, id int64) error
+
+	// pending messages
+	InsertPending(p *models.PendingMessage) error
+	ListPending(chatID int64) ([]models.PendingMessage, error)
+	TouchReminder(id int64, now time.Time)
+	SnoozePending(chatID int64, dateKey string, until time.Time) (bool, error)
+	DeletePending(chatID int64, dateKey string) error
+	HasPending(chatID int64, dateKey string) bool
+	HasAnswered(chatID int64, dateKey string) bool
+	HasPendingOrAnswered(chatID int64, dateKey string) bool
+
+	// prompt marks (missed / skipped)
+	MarkPrompt(chatID int64, dateKey, status string) error
+	GetPromptMark(chatID int64, dateKey string) (string, error)
+	ListPromptMarks(chatID int64, status string) ([]models.PromptMark, error)
+
+	// maintenance
+	ClearData(chatID int64) error
+	DropAll() error
+	Close() error
+}
@@ -0,0 +1,536 @@
+// Package storetest — общий набор проверок для реализаций storage.Store.
+// Подключается из теста конкретного драйвера:
+//
+//	func TestPostgres(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) storage.Store { … })
+//	}
+//
+// Для PostgreSQL достаточно локального контейнера:
+//
+//	docker run --rm -p 5432:5432 -e POSTGRES_PASSWORD=pg postgres:16
+//	TEST_DATABASE_URL=postgres://postgres:pg@localhost:5432/postgres go test ./internal/storage
+package storetest
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"telegram-health-dairy/internal/models"
+	"telegram-health-dairy/internal/storage"
+)
+
+// Factory возвращает пустое хранилище с применёнными миграциями.
+type Factory func(t *testing.T) storage.Store
+
+// Run прогоняет все проверки, каждую — на свежем хранилище.
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s storage.Store)
+	}{
+		{"Users", testUsers},
+		{"Sessions", testSessions},
+		{"Conversations", testConversations},
+		{"DayRecords", testDayRecords},
+		{"ImportDayRecords", testImportDayRecords},
+		{"CheckIns", testCheckIns},
+		{"Symptoms", testSymptoms},
+		{"Meals", testMeals},
+		{"DinnerTags", testDinnerTags},
+		{"Pending", testPending},
+		{"PromptMarks", testPromptMarks},
+		{"ClearData", testClearData},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newStore(t)
+			t.Cleanup(func() { s.Close() })
+			tc.fn(t, s)
+		})
+	}
+}
+
+const chatID = int64(100500)
+
+func mustUser(t *testing.T, s storage.Store) {
+	t.Helper()
+	err := s.UpsertUser(&models.User{
+		ChatID: chatID, TZ: "Europe/Moscow", MorningAt: "10:00", EveningAt: "18:00",
+	})
+	if err != nil {
+		t.Fatalf("UpsertUser: %v", err)
+	}
+}
+
+func testUsers(t *testing.T, s storage.Store) {
+	if u, err := s.GetUser(chatID); err != nil || u != nil {
+		t.Fatalf("GetUser(missing) = %v, %v; want nil, nil", u, err)
+	}
+	mustUser(t, s)
+
+	u, err := s.GetUser(chatID)
+	if err != nil || u == nil {
+		t.Fatalf("GetUser = %v, %v", u, err)
+	}
+	created := u.CreatedAt
+
+	u.MorningAt, u.TZ, u.Lang = "07:30", "+03:00", "en"
+	u.RemindEvery, u.RemindMax, u.QuietFrom, u.QuietTo = 45, 2, "23:00", "08:00"
+	if err := s.UpsertUser(u); err != nil {
+		t.Fatalf("UpsertUser(update): %v", err)
+	}
+	u, _ = s.GetUser(chatID)
+	if u.MorningAt != "07:30" || u.TZ != "+03:00" || u.EveningAt != "18:00" || u.Lang != "en" {
+		t.Errorf("after update got %+v", u)
+	}
+	if u.RemindEvery != 45 || u.RemindMax != 2 || u.QuietFrom != "23:00" || u.QuietTo != "08:00" {
+		t.Errorf("reminder policy after update = %+v", u)
+	}
+	if u.CreatedAt != created {
+		t.Errorf("CreatedAt changed on update: %d → %d", created, u.CreatedAt)
+	}
+
+	users, err := s.ListUsers()
+	if err != nil || len(users) != 1 {
+		t.Fatalf("ListUsers = %v, %v; want 1 user", users, err)
+	}
+	idle, err := s.ListIdleUsers()
+	if err != nil || len(idle) != 1 {
+		t.Fatalf("ListIdleUsers (no session) = %v, %v; want 1 user", idle, err)
+	}
+	_ = s.SetSessionState(chatID, models.StateWaitingMorning)
+	if idle, _ = s.ListIdleUsers(); len(idle) != 0 {
+		t.Errorf("ListIdleUsers (waiting) = %v; want none", idle)
+	}
+}
+
+func testSessions(t *testing.T, s storage.Store) {
+	st, err := s.GetSessionState(chatID)
+	if err != nil || st != models.StateNotStarted {
+		t.Fatalf("GetSessionState(missing) = %q, %v; want %q", st, err, models.StateNotStarted)
+	}
+	for _, want := range []models.State{models.StateInitial, models.StateWaitingEvening} {
+		if err := s.SetSessionState(chatID, want); err != nil {
+			t.Fatalf("SetSessionState: %v", err)
+		}
+		if got, _ := s.GetSessionState(chatID); got != want {
+			t.Errorf("GetSessionState = %q; want %q", got, want)
+		}
+	}
+
+	_ = s.SetSessionState(chatID+1, models.StateWaitingMorning)
+	_ = s.SetSessionState(chatID+2, models.StateIdle)
+	chats, err := s.ListChatsByState(models.StateWaitingMorning, models.StateWaitingEvening)
+	if err != nil || len(chats) != 2 {
+		t.Errorf("ListChatsByState = %v, %v; want 2 chats", chats, err)
+	}
+}
+
+func testConversations(t *testing.T, s storage.Store) {
+	if c, err := s.GetConversation(chatID); err != nil || c != nil {
+		t.Fatalf("GetConversation(missing) = %+v, %v", c, err)
+	}
+	_ = s.SetConversation(chatID, &models.Conversation{Flow: "setup", Step: "morning"})
+	want := &models.Conversation{
+		Flow: "complaints", Step: "confirm", Key: "2025-05-08-morning",
+		Data: map[string]string{"wait": "изжога"}, Expires: 1746700000,
+	}
+	if err := s.SetConversation(chatID, want); err != nil {
+		t.Fatalf("SetConversation: %v", err)
+	}
+	got, err := s.GetConversation(chatID)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetConversation = %+v, %v; want %+v", got, err, want)
+	}
+
+	if err := s.SetConversation(chatID, nil); err != nil {
+		t.Fatalf("SetConversation(nil): %v", err)
+	}
+	if c, _ := s.GetConversation(chatID); c != nil {
+		t.Errorf("GetConversation after reset = %+v", c)
+	}
+}
+
+func testDayRecords(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+
+	dinner := time.Date(2025, 5, 7, 19, 30, 0, 0, time.UTC)
+	if err := s.SetDinner(chatID, "2025-05-07", dinner); err != nil {
+		t.Fatalf("SetDinner: %v", err)
+	}
+	rec, err := s.GetDayRecord(chatID, "2025-05-07")
+	if err != nil || rec == nil {
+		t.Fatalf("GetDayRecord(dinner only) = %v, %v", rec, err)
+	}
+	if rec.Complaints != "" || rec.DinnerAt == nil || !rec.DinnerAt.Equal(dinner) {
+		t.Errorf("dinner-only record = %+v", rec)
+	}
+
+	if err := s.UpsertDayRecord(chatID, "2025-05-08", "изжога"); err != nil {
+		t.Fatalf("UpsertDayRecord: %v", err)
+	}
+	_ = s.UpsertDayRecord(chatID, "2025-05-08", "нет")
+	if !s.HasAnswered(chatID, "2025-05-08-morning") {
+		t.Error("HasAnswered(morning) = false after UpsertDayRecord")
+	}
+	if !s.HasAnswered(chatID, "2025-05-07-evening") {
+		t.Error("HasAnswered(evening) = false after SetDinner")
+	}
+
+	recs, err := s.ListDayRecords(chatID, "2025-05-01", "2025-05-31")
+	if err != nil || len(recs) != 2 {
+		t.Fatalf("ListDayRecords = %v, %v; want 2", recs, err)
+	}
+	if recs[0].Day != "2025-05-07" || recs[1].Complaints != "нет" {
+		t.Errorf("ListDayRecords order/content = %+v", recs)
+	}
+	if recs, _ = s.ListDayRecords(chatID, "2025-05-08", "2025-05-08"); len(recs) != 1 {
+		t.Errorf("ListDayRecords(single day) = %d records", len(recs))
+	}
+	if day, err := s.FirstDay(chatID); err != nil || day != "2025-05-07" {
+		t.Errorf("FirstDay = %q, %v; want 2025-05-07", day, err)
+	}
+	if day, err := s.FirstDay(chatID + 1); err != nil || day != "" {
+		t.Errorf("FirstDay(no records) = %q, %v; want empty", day, err)
+	}
+}
+
+func testImportDayRecords(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	_ = s.UpsertDayRecord(chatID, "2025-05-07", "изжога")
+
+	dinner := time.Date(2025, 5, 7, 19, 30, 0, 0, time.UTC)
+	err := s.ImportDayRecords(chatID, []models.DayRecord{
+		{Day: "2025-05-07", DinnerAt: &dinner},
+		{Day: "2025-05-08", Complaints: "нет"},
+	}, false)
+	if err != nil {
+		t.Fatalf("ImportDayRecords: %v", err)
+	}
+
+	recs, err := s.ListDayRecords(chatID, "2025-05-01", "2025-05-31")
+	if err != nil || len(recs) != 2 {
+		t.Fatalf("ListDayRecords = %v, %v; want 2", recs, err)
+	}
+	if recs[0].Complaints != "изжога" || recs[0].DinnerAt == nil || !recs[0].DinnerAt.Equal(dinner) {
+		t.Errorf("merged record = %+v; want complaints kept and dinner set", recs[0])
+	}
+	if recs[1].Complaints != "нет" || recs[1].DinnerAt != nil {
+		t.Errorf("new record = %+v", recs[1])
+	}
+
+	// без replace сохранённое не меняется: чек-ин только дополняется
+	score, other := 5, 2
+	_ = s.SaveCheckIn(chatID, "2025-05-09", &models.CheckIn{Score: &score}, "")
+	_ = s.SaveCheckIn(chatID, "2025-05-10", &models.CheckIn{Symptoms: []string{"heartburn"}}, "")
+	err = s.ImportDayRecords(chatID, []models.DayRecord{
+		{Day: "2025-05-07", Complaints: "тошнота"},
+		{Day: "2025-05-09", CheckIn: &models.CheckIn{Symptoms: []string{"nausea"}}},
+		{Day: "2025-05-10", CheckIn: &models.CheckIn{Score: &other, Symptoms: []string{"nausea"}}},
+	}, false)
+	if err != nil {
+		t.Fatalf("ImportDayRecords(fill): %v", err)
+	}
+	recs, _ = s.ListDayRecords(chatID, "2025-05-07", "2025-05-10")
+	if recs[0].Complaints != "изжога" {
+		t.Errorf("complaints after fill = %q; want kept", recs[0].Complaints)
+	}
+	if c := recs[2].CheckIn; c == nil || c.Score == nil || *c.Score != 5 || !slices.Equal(c.Symptoms, []string{"nausea"}) {
+		t.Errorf("check-in after fill = %+v; want score kept and symptom added", c)
+	}
+	if c := recs[3].CheckIn; c == nil || c.Score == nil || *c.Score != 2 || !slices.Equal(c.Symptoms, []string{"heartburn"}) {
+		t.Errorf("check-in after fill = %+v; want score added and symptoms kept", c)
+	}
+
+	// replace перезаписывает заданные поля и чек-ин целиком
+	err = s.ImportDayRecords(chatID, []models.DayRecord{
+		{Day: "2025-05-07", Complaints: "тошнота"},
+		{Day: "2025-05-09", CheckIn: &models.CheckIn{Symptoms: []string{"bloating"}}},
+	}, true)
+	if err != nil {
+		t.Fatalf("ImportDayRecords(replace): %v", err)
+	}
+	recs, _ = s.ListDayRecords(chatID, "2025-05-07", "2025-05-09")
+	if recs[0].Complaints != "тошнота" || recs[0].DinnerAt == nil {
+		t.Errorf("record after replace = %+v; want complaints replaced and dinner kept", recs[0])
+	}
+	if c := recs[2].CheckIn; c == nil || c.Score != nil || !slices.Equal(c.Symptoms, []string{"bloating"}) {
+		t.Errorf("check-in after replace = %+v", c)
+	}
+}
+
+func testCheckIns(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	_ = s.SetDinner(chatID, "2025-05-08", time.Date(2025, 5, 8, 19, 0, 0, 0, time.UTC))
+
+	score := 4
+	c := &models.CheckIn{Score: &score, Symptoms: []string{"nausea", "heartburn"}}
+	if err := s.SaveCheckIn(chatID, "2025-05-08", c, "после кофе"); err != nil {
+		t.Fatalf("SaveCheckIn: %v", err)
+	}
+	if !s.HasAnswered(chatID, "2025-05-08-morning") {
+		t.Error("HasAnswered(morning) = false after SaveCheckIn")
+	}
+	rec, err := s.GetDayRecord(chatID, "2025-05-08")
+	if err != nil || rec == nil || rec.CheckIn == nil {
+		t.Fatalf("GetDayRecord = %+v, %v; want check-in", rec, err)
+	}
+	if rec.Complaints != "после кофе" || rec.DinnerAt == nil {
+		t.Errorf("record = %+v; want note saved and dinner kept", rec)
+	}
+	got := rec.CheckIn
+	if got.NoComplaints || got.Score == nil || *got.Score != 4 ||
+		len(got.Symptoms) != 2 || got.Symptoms[0] != "heartburn" || got.Symptoms[1] != "nausea" {
+		t.Errorf("check-in = %+v", got)
+	}
+
+	// повторный ответ заменяет прежний целиком
+	if err := s.SaveCheckIn(chatID, "2025-05-08", &models.CheckIn{NoComplaints: true}, ""); err != nil {
+		t.Fatalf("SaveCheckIn(again): %v", err)
+	}
+	recs, err := s.ListDayRecords(chatID, "2025-05-01", "2025-05-31")
+	if err != nil || len(recs) != 1 || recs[0].CheckIn == nil {
+		t.Fatalf("ListDayRecords = %+v, %v", recs, err)
+	}
+	if got := recs[0].CheckIn; !got.NoComplaints || got.Score != nil || len(got.Symptoms) != 0 || recs[0].Complaints != "" {
+		t.Errorf("replaced check-in = %+v, complaints %q", got, recs[0].Complaints)
+	}
+
+	// импорт пишет чек-ин только там, где он задан
+	err = s.ImportDayRecords(chatID, []models.DayRecord{
+		{Day: "2025-05-08", Complaints: "изжога"},
+		{Day: "2025-05-09", CheckIn: &models.CheckIn{Score: &score, Symptoms: []string{"bloating"}}},
+	}, false)
+	if err != nil {
+		t.Fatalf("ImportDayRecords: %v", err)
+	}
+	recs, _ = s.ListDayRecords(chatID, "2025-05-01", "2025-05-31")
+	if len(recs) != 2 || recs[0].CheckIn == nil || !recs[0].CheckIn.NoComplaints {
+		t.Fatalf("after import = %+v; want first check-in kept", recs)
+	}
+	if c := recs[1].CheckIn; c == nil || c.Score == nil || len(c.Symptoms) != 1 {
+		t.Errorf("imported check-in = %+v", c)
+	}
+
+	if err := s.ClearData(chatID); err != nil {
+		t.Fatalf("ClearData: %v", err)
+	}
+	mustUser(t, s)
+	if rec, _ := s.GetDayRecord(chatID, "2025-05-09"); rec != nil {
+		t.Errorf("record after ClearData = %+v", rec)
+	}
+}
+
+func testSymptoms(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	list, err := s.ListSymptoms(chatID)
+	if err != nil || len(list) != len(models.DefaultSymptoms) || list[0].Key != models.DefaultSymptoms[0] {
+		t.Fatalf("ListSymptoms(new user) = %+v, %v; want defaults", list, err)
+	}
+
+	added, err := s.AddSymptom(chatID, "Слабость")
+	if err != nil {
+		t.Fatalf("AddSymptom: %v", err)
+	}
+	if slices.Contains(models.DefaultSymptoms, added.Key) {
+		t.Errorf("AddSymptom key %q clashes with a default", added.Key)
+	}
+	_ = s.RenameSymptom(chatID, "heartburn", "Жжение")
+	_ = s.ArchiveSymptom(chatID, "bloating", true)
+	_ = s.ReorderSymptoms(chatID, []string{added.Key, "heartburn"})
+
+	list, _ = s.ListSymptoms(chatID)
+	if len(list) != len(models.DefaultSymptoms)+1 {
+		t.Fatalf("ListSymptoms = %d symptoms", len(list))
+	}
+	if list[0].Key != added.Key || list[0].Name != "Слабость" || list[1].Name != "Жжение" {
+		t.Errorf("order/names = %+v", list[:2])
+	}
+	if last := list[len(list)-1]; last.Key != "bloating" || !last.Archived {
+		t.Errorf("archived symptom = %+v; want last and archived", last)
+	}
+
+	again, _ := s.AddSymptom(chatID, "Ещё")
+	if again.Key == added.Key {
+		t.Errorf("AddSymptom reused key %q", again.Key)
+	}
+	_ = s.ClearData(chatID)
+	mustUser(t, s)
+	if list, _ = s.ListSymptoms(chatID); len(list) != len(models.DefaultSymptoms) {
+		t.Errorf("ListSymptoms after ClearData = %+v; want defaults", list)
+	}
+}
+
+func testMeals(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	at := time.Date(2025, 5, 8, 13, 0, 0, 0, time.UTC)
+	lunch := &models.Meal{ChatID: chatID, Day: "2025-05-08", At: at, Kind: models.MealLunch,
+		Text: "борщ", Tags: []string{"fatty", "spicy"}}
+	breakfast := &models.Meal{ChatID: chatID, Day: "2025-05-08", At: at.Add(-5 * time.Hour),
+		Kind: models.MealBreakfast, PhotoID: "photo1"}
+	for _, m := range []*models.Meal{lunch, breakfast} {
+		if err := s.AddMeal(m); err != nil {
+			t.Fatalf("AddMeal: %v", err)
+		}
+	}
+	if lunch.ID == 0 || lunch.ID == breakfast.ID {
+		t.Fatalf("AddMeal ids = %d, %d", lunch.ID, breakfast.ID)
+	}
+	_ = s.AddMeal(&models.Meal{ChatID: chatID, Day: "2025-05-09", At: at.Add(24 * time.Hour), Kind: models.MealSnack})
+
+	meals, err := s.ListMeals(chatID, "2025-05-08", "2025-05-08")
+	if err != nil || len(meals) != 2 {
+		t.Fatalf("ListMeals = %+v, %v; want 2", meals, err)
+	}
+	if meals[0].ID != breakfast.ID || meals[0].PhotoID != "photo1" || len(meals[0].Tags) != 0 {
+		t.Errorf("first meal = %+v; want breakfast", meals[0])
+	}
+	if m := meals[1]; m.Text != "борщ" || !m.At.Equal(at) || !reflect.DeepEqual(m.Tags, []string{"fatty", "spicy"}) {
+		t.Errorf("second meal = %+v", m)
+	}
+
+	if err := s.DeleteMeal(chatID, lunch.ID); err != nil {
+		t.Fatalf("DeleteMeal: %v", err)
+	}
+	if meals, _ = s.ListMeals(chatID, "2025-05-01", "2025-05-31"); len(meals) != 2 {
+		t.Errorf("after DeleteMeal = %d meals; want 2", len(meals))
+	}
+	_ = s.ClearData(chatID)
+	if meals, _ = s.ListMeals(chatID, "2025-05-01", "2025-05-31"); len(meals) != 0 {
+		t.Errorf("meals after ClearData = %+v", meals)
+	}
+}
+
+func testDinnerTags(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	dinner := time.Date(2025, 5, 8, 19, 30, 0, 0, time.UTC)
+	if err := s.SetDinnerTags(chatID, "2025-05-08", []string{"spicy"}); err == nil {
+		t.Errorf("SetDinnerTags без ужина: нет ошибки")
+	}
+	_ = s.SetDinner(chatID, "2025-05-08", dinner)
+	if err := s.SetDinnerTags(chatID, "2025-05-08", []string{"spicy", "шаурма"}); err != nil {
+		t.Fatalf("SetDinnerTags: %v", err)
+	}
+	if err := s.SetDinnerTags(chatID, "2025-05-08", []string{"alcohol", "шаурма"}); err != nil {
+		t.Fatalf("SetDinnerTags again: %v", err)
+	}
+	rec, err := s.GetDayRecord(chatID, "2025-05-08")
+	if err != nil || rec == nil {
+		t.Fatalf("GetDayRecord = %v, %v", rec, err)
+	}
+	if want := []string{"alcohol", "шаурма"}; !reflect.DeepEqual(rec.DinnerTags, want) {
+		t.Errorf("DinnerTags = %v; want %v", rec.DinnerTags, want)
+	}
+
+	_ = s.AddMeal(&models.Meal{ChatID: chatID, Day: "2025-05-09", At: dinner.Add(16 * time.Hour),
+		Kind: models.MealLunch, Tags: []string{"coffee", "alcohol"}})
+	_ = s.AddMeal(&models.Meal{ChatID: chatID, Day: "2025-05-07", At: dinner.Add(-32 * time.Hour),
+		Kind: models.MealLunch, Tags: []string{"борщ", "alcohol"}})
+	tags, err := s.ListFoodTags(chatID)
+	if want := []string{"alcohol", "coffee", "шаурма", "борщ"}; err != nil || !reflect.DeepEqual(tags, want) {
+		t.Errorf("ListFoodTags = %v, %v; want %v", tags, err, want)
+	}
+}
+
+func testPending(t *testing.T, s storage.Store) {
+	now := time.Now()
+	old := now.Add(-time.Hour).Unix()
+	p := &models.PendingMessage{
+		ChatID: chatID, DateKey: "2025-05-08-morning", Type: "morning",
+		MsgID: 42, CreatedAt: old, RemindedAt: old,
+	}
+	if err := s.InsertPending(p); err != nil {
+		t.Fatalf("InsertPending: %v", err)
+	}
+	if !s.HasPending(chatID, p.DateKey) || !s.HasPendingOrAnswered(chatID, p.DateKey) {
+		t.Fatal("HasPending = false after InsertPending")
+	}
+
+	p.MsgID = 43
+	if err := s.InsertPending(p); err != nil {
+		t.Fatalf("InsertPending(replace): %v", err)
+	}
+	list, err := s.ListPending(chatID)
+	if err != nil || len(list) != 1 || list[0].MsgID != 43 || list[0].Reminders != 0 {
+		t.Fatalf("ListPending = %+v, %v; want one with MsgID 43", list, err)
+	}
+
+	s.TouchReminder(list[0].ID, now)
+	s.TouchReminder(list[0].ID, now)
+	list, _ = s.ListPending(chatID)
+	if len(list) != 1 || list[0].RemindedAt != now.Unix() || list[0].Reminders != 2 {
+		t.Errorf("ListPending after touch = %+v; want reminded_at %d and 2 reminders", list, now.Unix())
+	}
+
+	until := now.Add(time.Hour)
+	if ok, err := s.SnoozePending(chatID, p.DateKey, until); !ok || err != nil {
+		t.Fatalf("SnoozePending = %v, %v", ok, err)
+	}
+	if ok, err := s.SnoozePending(chatID, "2025-05-08-evening", until); ok || err != nil {
+		t.Errorf("SnoozePending(missing) = %v, %v; want false", ok, err)
+	}
+	list, _ = s.ListPending(chatID)
+	if len(list) != 1 || list[0].SnoozeUntil != until.Unix() || !list[0].Snoozed() {
+		t.Errorf("ListPending after snooze = %+v", list)
+	}
+
+	// новый вопрос на тот же ключ начинает счёт напоминаний заново
+	if err := s.InsertPending(p); err != nil {
+		t.Fatalf("InsertPending(again): %v", err)
+	}
+	list, _ = s.ListPending(chatID)
+	if len(list) != 1 || list[0].Reminders != 0 || list[0].RemindedAt != old || list[0].SnoozeUntil != 0 {
+		t.Errorf("ListPending after re-insert = %+v", list)
+	}
+
+	if err := s.DeletePending(chatID, p.DateKey); err != nil {
+		t.Fatalf("DeletePending: %v", err)
+	}
+	if s.HasPending(chatID, p.DateKey) {
+		t.Error("HasPending = true after DeletePending")
+	}
+}
+
+func testPromptMarks(t *testing.T, s storage.Store) {
+	if m, err := s.GetPromptMark(chatID, "2025-05-08-morning"); err != nil || m != "" {
+		t.Fatalf("GetPromptMark(missing) = %q, %v", m, err)
+	}
+	_ = s.MarkPrompt(chatID, "2025-05-08-morning", models.MarkMissed)
+	_ = s.MarkPrompt(chatID, "2025-05-07-evening", models.MarkMissed)
+	if err := s.MarkPrompt(chatID, "2025-05-08-morning", models.MarkSkipped); err != nil {
+		t.Fatalf("MarkPrompt(update): %v", err)
+	}
+
+	if m, _ := s.GetPromptMark(chatID, "2025-05-08-morning"); m != models.MarkSkipped {
+		t.Errorf("GetPromptMark = %q; want %q", m, models.MarkSkipped)
+	}
+	missed, err := s.ListPromptMarks(chatID, models.MarkMissed)
+	if err != nil || len(missed) != 1 || missed[0].DateKey != "2025-05-07-evening" {
+		t.Errorf("ListPromptMarks(missed) = %+v, %v", missed, err)
+	}
+}
+
+func testClearData(t *testing.T, s storage.Store) {
+	mustUser(t, s)
+	_ = s.SetSessionState(chatID, models.StateIdle)
+	_ = s.SetConversation(chatID, &models.Conversation{Flow: "setup", Step: "evening"})
+	_ = s.UpsertDayRecord(chatID, "2025-05-08", "изжога")
+
+	if err := s.ClearData(chatID); err != nil {
+		t.Fatalf("ClearData: %v", err)
+	}
+	if u, _ := s.GetUser(chatID); u != nil {
+		t.Error("user survived ClearData")
+	}
+	if rec, _ := s.GetDayRecord(chatID, "2025-05-08"); rec != nil {
+		t.Error("day record survived ClearData")
+	}
+	if st, _ := s.GetSessionState(chatID); st != models.StateNotStarted {
+		t.Errorf("session state after ClearData = %q", st)
+	}
+	if c, _ := s.GetConversation(chatID); c != nil {
+		t.Errorf("conversation after ClearData = %+v", c)
+	}
+}
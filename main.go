@@ -1,24 +1,63 @@
 package main
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	_ "time/tzdata"
 
 	"telegram-health-dairy/internal/bot"
+	"telegram-health-dairy/internal/clock"
 	"telegram-health-dairy/internal/config"
 	"telegram-health-dairy/internal/handlers"
 	"telegram-health-dairy/internal/storage"
 	"telegram-health-dairy/internal/utils"
 )
 
+// Коды выхода: 0 — остановлен сигналом и всё закрыто чисто,
+// 1 — приём апдейтов упал или остановка не уложилась в таймаут.
+const (
+	exitOK      = 0
+	exitFailure = 1
+)
+
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	cfg := config.Load()
 
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	bot, err := bot.New(cfg.TelegramToken)
 	utils.LogFor(err)
 
-	db, err := storage.New(cfg.DBName)
+	db, err := storage.Open(cfg.DBDriver, cfg.DBDSN)
 	utils.LogFor(err)
 
-	handlers.Register(bot.API, db)
-	bot.Run()
+	h, err := handlers.Register(ctx, bot.API, db, clock.Real(), cfg)
+	utils.LogFor(err)
+
+	code := exitOK
+	if err := h.Wait(); err != nil {
+		log.Printf("❌ Приём апдейтов остановлен: %v", err)
+		code = exitFailure
+	}
+
+	log.Printf("🛑 Останавливаемся (таймаут %s)…", cfg.ShutdownTimeout)
+	if err := h.Shutdown(cfg.ShutdownTimeout); err != nil {
+		log.Printf("⚠️ Остановка: %v", err)
+		code = exitFailure
+	}
+	if err := db.Close(); err != nil {
+		log.Printf("⚠️ Закрытие БД: %v", err)
+		code = exitFailure
+	}
+
+	log.Printf("👋 Бот остановлен")
+	return code
 }